@@ -0,0 +1,300 @@
+package bds
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/df-mc/dragonfly/server/world/mcdb/leveldat"
+)
+
+// readyLine is the line that BDS writes to stdout once it has started and accepts connections.
+const readyLine = "Server started."
+
+// Server is a local installation of the Bedrock Dedicated Server that may be configured and started as a child
+// process of the tool.
+type Server struct {
+	// Dir is the directory that BDS is installed in. It holds the server.properties file and the worlds
+	// directory.
+	Dir string
+	// Executable is the path to the executable that is started. If left empty, bedrock_server (or
+	// bedrock_server.exe on Windows) within Dir is used.
+	Executable string
+	// LevelName is the name of the world that the server loads. If left empty, "datagen" is used.
+	LevelName string
+	// Port is the IPv4 port that the server listens on. If left 0, 19132 is used. The IPv6 port is always set to
+	// the port directly after it.
+	Port int
+	// Education specifies if education features should be enabled in the world.
+	Education bool
+	// Experiments is a list of experiments, such as "gametest" or "villager_trades_rebalance", that should be
+	// enabled in the world. Experiments that were previously enabled in the world but are not in this list are
+	// disabled.
+	Experiments []string
+	// ReadyTimeout is the maximum duration to wait for the server to start. If left 0, two minutes is used.
+	ReadyTimeout time.Duration
+	// StopTimeout is the maximum duration to wait for the server to exit after issuing the stop command, after
+	// which it is killed. If left 0, 30 seconds is used.
+	StopTimeout time.Duration
+	// Log is a writer that all output of the server is copied to. If nil, the output is discarded.
+	Log io.Writer
+}
+
+// Addr returns the address that the server listens on once started.
+func (s Server) Addr() string {
+	return "127.0.0.1:" + strconv.Itoa(s.port())
+}
+
+// Run configures the server, starts it and waits for it to become ready, after which f is called with the address
+// of the server. The server is stopped once f returns, regardless of whether it returned an error.
+func (s Server) Run(ctx context.Context, f func(addr string) error) error {
+	if err := s.Configure(); err != nil {
+		return err
+	}
+	p, err := s.Start(ctx)
+	if err != nil {
+		return err
+	}
+	ferr := f(s.Addr())
+	if err := p.Stop(); err != nil {
+		return errors.Join(ferr, err)
+	}
+	return ferr
+}
+
+// Configure writes the server.properties and level.dat settings required to generate data with the tool to the
+// server directory. Existing properties and world settings that are not required by the tool are left
+// untouched.
+func (s Server) Configure() error {
+	if err := s.configureProperties(); err != nil {
+		return fmt.Errorf("configure server.properties: %w", err)
+	}
+	if err := s.configureWorld(); err != nil {
+		return fmt.Errorf("configure level.dat: %w", err)
+	}
+	return nil
+}
+
+// configureProperties updates the server.properties file of the server, adding the properties that are not
+// yet present in the file.
+func (s Server) configureProperties() error {
+	properties := []struct{ key, value string }{
+		{"level-name", s.levelName()},
+		{"server-port", strconv.Itoa(s.port())},
+		{"server-portv6", strconv.Itoa(s.port() + 1)},
+		{"block-network-ids-are-hashes", "false"},
+	}
+	path := filepath.Join(s.Dir, "server.properties")
+	b, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	var lines []string
+	if len(b) > 0 {
+		lines = strings.Split(strings.TrimRight(string(b), "\r\n"), "\n")
+	}
+	for _, p := range properties {
+		line, found := p.key+"="+p.value, false
+		for i, l := range lines {
+			if k, _, ok := strings.Cut(strings.TrimSpace(l), "="); ok && k == p.key {
+				lines[i], found = line, true
+			}
+		}
+		if !found {
+			lines = append(lines, line)
+		}
+	}
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+// configureWorld updates the level.dat of the world loaded by the server to enable education features and the
+// experiments of the Server. If the world does not yet exist, a level.dat with default settings is created so
+// that the server generates the world using these settings.
+func (s Server) configureWorld() error {
+	dir := filepath.Join(s.Dir, "worlds", s.levelName())
+	path := filepath.Join(dir, "level.dat")
+
+	ldat, err := leveldat.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		var d leveldat.Data
+		d.FillDefault()
+		d.LevelName = s.levelName()
+		ldat = new(leveldat.LevelDat)
+		err = ldat.Marshal(d)
+	}
+	if err != nil {
+		return err
+	}
+	var props map[string]any
+	if err := ldat.Unmarshal(&props); err != nil {
+		return err
+	}
+
+	experiments := make(map[string]any, len(s.Experiments)+2)
+	for _, name := range s.Experiments {
+		experiments[name] = uint8(1)
+	}
+	if len(s.Experiments) > 0 {
+		experiments["experiments_ever_used"] = uint8(1)
+		experiments["saved_with_toggled_experiments"] = uint8(1)
+	}
+	props["experiments"] = experiments
+	props["educationFeaturesEnabled"] = boolByte(s.Education)
+
+	if err := ldat.Marshal(props); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return ldat.WriteFile(path)
+}
+
+// Start starts the server as a child process and blocks until it is ready to accept connections, the context is
+// cancelled or the ReadyTimeout passes. The server is killed if it does not become ready.
+func (s Server) Start(ctx context.Context) (*Process, error) {
+	timeout := s.ReadyTimeout
+	if timeout == 0 {
+		timeout = time.Minute * 2
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Relative paths of the executable are resolved relative to the working directory of the command, so both
+	// the executable and the directory are made absolute first.
+	exe, err := filepath.Abs(s.executable())
+	if err != nil {
+		return nil, fmt.Errorf("start server: %w", err)
+	}
+	dir, err := filepath.Abs(s.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("start server: %w", err)
+	}
+	cmd := exec.Command(exe)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "LD_LIBRARY_PATH="+dir)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("start server: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("start server: %w", err)
+	}
+	cmd.Stderr = cmd.Stdout
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start server: %w", err)
+	}
+	stopTimeout := s.StopTimeout
+	if stopTimeout == 0 {
+		stopTimeout = time.Second * 30
+	}
+	p := &Process{cmd: cmd, stdin: stdin, stopTimeout: stopTimeout, exited: make(chan struct{})}
+
+	ready := make(chan struct{})
+	go p.watch(stdout, s.Log, ready)
+
+	select {
+	case <-ready:
+		return p, nil
+	case <-p.exited:
+		return nil, fmt.Errorf("start server: exited before becoming ready (%v)", p.err)
+	case <-ctx.Done():
+		_ = p.kill()
+		return nil, fmt.Errorf("start server: %w", context.Cause(ctx))
+	}
+}
+
+// executable returns the path to the executable that should be started for the server.
+func (s Server) executable() string {
+	if s.Executable != "" {
+		return s.Executable
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(s.Dir, "bedrock_server.exe")
+	}
+	return filepath.Join(s.Dir, "bedrock_server")
+}
+
+// levelName returns the name of the world loaded by the server.
+func (s Server) levelName() string {
+	if s.LevelName == "" {
+		return "datagen"
+	}
+	return s.LevelName
+}
+
+// port returns the IPv4 port that the server listens on.
+func (s Server) port() int {
+	if s.Port == 0 {
+		return 19132
+	}
+	return s.Port
+}
+
+// Process is a running BDS child process started using Server.Start.
+type Process struct {
+	cmd         *exec.Cmd
+	stdin       io.WriteCloser
+	stopTimeout time.Duration
+	exited      chan struct{}
+	err         error
+}
+
+// watch reads the output of the process line by line, copying it to log if non-nil and closing ready once the
+// server reports that it has started. When the output is closed, watch waits for the process to exit.
+func (p *Process) watch(stdout io.Reader, log io.Writer, ready chan struct{}) {
+	scanner := bufio.NewScanner(stdout)
+	started := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		if log != nil {
+			_, _ = fmt.Fprintln(log, line)
+		}
+		if !started && strings.Contains(line, readyLine) {
+			started = true
+			close(ready)
+		}
+	}
+	p.err = p.cmd.Wait()
+	close(p.exited)
+}
+
+// Stop stops the server by issuing the stop command. If the server does not exit within the StopTimeout of the
+// Server, it is killed.
+func (p *Process) Stop() error {
+	_, _ = io.WriteString(p.stdin, "stop\n")
+	select {
+	case <-p.exited:
+		return nil
+	case <-time.After(p.stopTimeout):
+		return p.kill()
+	}
+}
+
+// kill kills the process and waits for it to exit.
+func (p *Process) kill() error {
+	if err := p.cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return fmt.Errorf("kill server: %w", err)
+	}
+	<-p.exited
+	return nil
+}
+
+// boolByte converts a bool to the byte representation used in level.dat files.
+func boolByte(b bool) uint8 {
+	if b {
+		return 1
+	}
+	return 0
+}
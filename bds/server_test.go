@@ -0,0 +1,207 @@
+package bds
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/df-mc/dragonfly/server/world/mcdb/leveldat"
+)
+
+// stubEnv is the environment variable that makes the test binary behave like a stub server instead of running
+// the tests. Its value is the behaviour of the stub.
+const stubEnv = "BDS_STUB"
+
+func TestMain(m *testing.M) {
+	switch os.Getenv(stubEnv) {
+	case "":
+		os.Exit(m.Run())
+	case "ready":
+		// Behave like BDS: report that the server started and exit once the stop command is read.
+		fmt.Println("[INFO] Starting Server")
+		fmt.Println("[INFO] " + readyLine)
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if strings.TrimSpace(scanner.Text()) == "stop" {
+				fmt.Println("[INFO] Stopping server...")
+				os.Exit(0)
+			}
+		}
+		os.Exit(0)
+	case "ignore-stop":
+		fmt.Println("[INFO] " + readyLine)
+		time.Sleep(time.Hour)
+	case "never-ready":
+		time.Sleep(time.Hour)
+	case "crash":
+		fmt.Println("[ERROR] failed to start")
+		os.Exit(3)
+	}
+	os.Exit(1)
+}
+
+// stubServer returns a Server in a temporary directory that starts the test binary as a stub with the behaviour
+// passed.
+func stubServer(t *testing.T, behaviour string) Server {
+	t.Setenv(stubEnv, behaviour)
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Server{Dir: t.TempDir(), Executable: exe, ReadyTimeout: time.Second * 10, StopTimeout: time.Second * 10}
+}
+
+func TestStartStop(t *testing.T) {
+	var log strings.Builder
+	s := stubServer(t, "ready")
+	s.Log = &log
+	p, err := s.Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if code := p.cmd.ProcessState.ExitCode(); code != 0 {
+		t.Errorf("stub exited with code %d, want 0", code)
+	}
+	if !strings.Contains(log.String(), readyLine) || !strings.Contains(log.String(), "Stopping server") {
+		t.Errorf("output of server not copied to log: %q", log.String())
+	}
+}
+
+func TestStartReadyTimeout(t *testing.T) {
+	s := stubServer(t, "never-ready")
+	s.ReadyTimeout = time.Millisecond * 200
+	start := time.Now()
+	if _, err := s.Start(context.Background()); err == nil {
+		t.Fatal("expected error for server that never becomes ready")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second*5 {
+		t.Errorf("Start returned after %v, expected it to return shortly after the ready timeout", elapsed)
+	}
+}
+
+func TestStartCancelled(t *testing.T) {
+	s := stubServer(t, "never-ready")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+	defer cancel()
+	if _, err := s.Start(ctx); err == nil {
+		t.Fatal("expected error for cancelled context")
+	}
+}
+
+func TestStartExited(t *testing.T) {
+	s := stubServer(t, "crash")
+	_, err := s.Start(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "exited before becoming ready") {
+		t.Fatalf("expected error for server exiting before becoming ready, got %v", err)
+	}
+}
+
+func TestStopKill(t *testing.T) {
+	s := stubServer(t, "ignore-stop")
+	s.StopTimeout = time.Millisecond * 200
+	p, err := s.Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if p.cmd.ProcessState.Success() {
+		t.Error("server ignoring the stop command exited successfully, expected it to be killed")
+	}
+}
+
+func TestRun(t *testing.T) {
+	s := stubServer(t, "ready")
+	s.Port = 19140
+	var got string
+	err := s.Run(context.Background(), func(addr string) error {
+		got = addr
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "127.0.0.1:19140" {
+		t.Errorf("got address %s, want 127.0.0.1:19140", got)
+	}
+}
+
+func TestConfigureProperties(t *testing.T) {
+	s := Server{Dir: t.TempDir(), LevelName: "test", Port: 19140}
+	path := filepath.Join(s.Dir, "server.properties")
+	existing := "server-name=Dedicated Server\nlevel-name=Bedrock level\nblock-network-ids-are-hashes=true\n"
+	if err := os.WriteFile(path, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Configure(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "server-name=Dedicated Server\nlevel-name=test\nblock-network-ids-are-hashes=false\nserver-port=19140\nserver-portv6=19141\n"
+	if string(b) != want {
+		t.Errorf("got server.properties:\n%s\nwant:\n%s", b, want)
+	}
+}
+
+func TestConfigureWorld(t *testing.T) {
+	s := Server{Dir: t.TempDir(), LevelName: "test", Education: true, Experiments: []string{"gametest"}}
+	if err := s.Configure(); err != nil {
+		t.Fatal(err)
+	}
+	props := readLevelDat(t, s)
+	if props["LevelName"] != "test" {
+		t.Errorf("got level name %v, want test", props["LevelName"])
+	}
+	if props["educationFeaturesEnabled"] != uint8(1) {
+		t.Errorf("education features not enabled: %v", props["educationFeaturesEnabled"])
+	}
+	experiments, _ := props["experiments"].(map[string]any)
+	for _, name := range []string{"gametest", "experiments_ever_used", "saved_with_toggled_experiments"} {
+		if experiments[name] != uint8(1) {
+			t.Errorf("experiment %s not enabled: %v", name, experiments)
+		}
+	}
+
+	// Configuring an existing world must replace the experiments and keep the other settings.
+	s.Education, s.Experiments = false, []string{"villager_trades_rebalance"}
+	if err := s.Configure(); err != nil {
+		t.Fatal(err)
+	}
+	props = readLevelDat(t, s)
+	if props["educationFeaturesEnabled"] != uint8(0) {
+		t.Errorf("education features not disabled: %v", props["educationFeaturesEnabled"])
+	}
+	experiments, _ = props["experiments"].(map[string]any)
+	if _, ok := experiments["gametest"]; ok || experiments["villager_trades_rebalance"] != uint8(1) {
+		t.Errorf("experiments not replaced: %v", experiments)
+	}
+	if props["LevelName"] != "test" {
+		t.Errorf("level name not kept: %v", props["LevelName"])
+	}
+}
+
+// readLevelDat reads the level.dat of the world of the Server passed.
+func readLevelDat(t *testing.T, s Server) map[string]any {
+	t.Helper()
+	ldat, err := leveldat.ReadFile(filepath.Join(s.Dir, "worlds", s.levelName(), "level.dat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var props map[string]any
+	if err := ldat.Unmarshal(&props); err != nil {
+		t.Fatal(err)
+	}
+	return props
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/df-mc/datagen/data"
+	"github.com/df-mc/datagen/session"
+	"github.com/df-mc/datagen/upgrade"
+	"github.com/df-mc/datagen/write"
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
+)
+
+// runCommand runs the command with the name and arguments passed, which generates data from existing files rather
+// than from a server. The data is written to the schemas directory rather than to the output directory, as the
+// output directory is replaced by every run against a server.
+func runCommand(name string, args []string) {
+	switch name {
+	case "block-schema":
+		if len(args) != 2 {
+			panic(fmt.Errorf("usage: block-schema <old palette> <new palette>"))
+		}
+		old, new := loadPalette(args[0]), loadPalette(args[1])
+		schema, warnings := upgrade.NewBlockSchema(old, new)
+		for _, w := range warnings {
+			fmt.Println("warning:", w)
+		}
+		write.JSON(write.DiskFS{}, fmt.Sprintf("schemas/blocks/%s_to_%s.json", old.Version(), new.Version()), schema)
+	case "item-schema":
+		if len(args) != 2 && len(args) != 4 {
+			panic(fmt.Errorf("usage: item-schema <old items> <new items> [<old palette> <new palette>]"))
+		}
+		oldItems, oldVersion := loadItems(args[0])
+		newItems, newVersion := loadItems(args[1])
+		var old, new *data.Palette
+		if len(args) == 4 {
+			old, new = loadPalette(args[2]), loadPalette(args[3])
+		} else {
+			// Without palettes passed explicitly, the bundles are chosen by the game versions of the item
+			// registries. If both resolve to the same bundle, block items would never be upgraded.
+			var oldBundle, newBundle string
+			old, oldBundle = data.PaletteFor(oldVersion)
+			new, newBundle = data.PaletteFor(newVersion)
+			if oldBundle == newBundle {
+				panic(fmt.Errorf("item registries of versions %q and %q both resolve to palette bundle %s: pass the old and new palettes explicitly", oldVersion, newVersion, oldBundle))
+			}
+		}
+		// The schema is named after the versions of the item registries, falling back to the block versions of
+		// the palettes for registries without a known version.
+		if oldVersion == "" {
+			oldVersion = old.Version()
+		}
+		if newVersion == "" {
+			newVersion = new.Version()
+		}
+		schema, warnings := upgrade.NewItemSchema(oldItems, newItems, old, new)
+		for _, w := range warnings {
+			fmt.Println("warning:", w)
+		}
+		path := fmt.Sprintf("schemas/items/%s_to_%s", oldVersion, newVersion)
+		write.JSON(write.DiskFS{}, path+".json", schema)
+		write.NBT(write.DiskFS{}, path+".nbt", schema.Dragonfly())
+	default:
+		panic(fmt.Errorf("unknown command %s", name))
+	}
+}
+
+// loadPalette loads the palette passed to a command, which is either the version of a palette bundle embedded in
+// the data package or a directory holding canonical_block_states.nbt and block_state_meta_map.json.
+func loadPalette(arg string) *data.Palette {
+	if slices.Contains(data.Bundles(), arg) {
+		p, err := data.BundlePalette(arg)
+		if err != nil {
+			panic(err)
+		}
+		return p
+	}
+	if _, err := os.Stat(arg); err != nil {
+		panic(fmt.Errorf("palette %s is neither an embedded bundle (%v) nor a directory: %w", arg, data.Bundles(), err))
+	}
+	p, err := data.LoadPalette(filepath.Join(arg, "canonical_block_states.nbt"), filepath.Join(arg, "block_state_meta_map.json"))
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// loadItems loads the names of the items in the item registry passed to a command, which is either a
+// vanilla_items.nbt or required_item_list.json file or an output directory of an earlier run holding one of them.
+// For output directories, the game version of the server the items were generated from is also returned.
+func loadItems(arg string) ([]string, string) {
+	var version string
+	path := arg
+	if info, err := os.Stat(arg); err != nil {
+		panic(fmt.Errorf("failed to read item registry: %w", err))
+	} else if info.IsDir() {
+		var manifest session.Manifest
+		if b, err := os.ReadFile(filepath.Join(arg, "manifest.json")); err == nil && json.Unmarshal(b, &manifest) == nil && len(manifest.Sessions) > 0 {
+			version = manifest.Sessions[0].GameVersion
+		}
+		path = filepath.Join(arg, "dragonfly/server/world/vanilla_items.nbt")
+		if _, err := os.Stat(path); err != nil {
+			path = filepath.Join(arg, "pocketmine/required_item_list.json")
+		}
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		panic(fmt.Errorf("failed to read item registry: %w", err))
+	}
+	var items map[string]any
+	if filepath.Ext(path) == ".nbt" {
+		err = nbt.Unmarshal(b, &items)
+	} else {
+		err = json.Unmarshal(b, &items)
+	}
+	if err != nil {
+		panic(fmt.Errorf("failed to unmarshal item registry %s: %w", path, err))
+	}
+	return slices.Sorted(maps.Keys(items)), version
+}
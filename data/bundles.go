@@ -0,0 +1,196 @@
+package data
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// bundles holds a directory for every palette bundle, named after the game version it belongs to, such as
+// "1.21.60". Each directory holds the canonical_block_states.nbt and block_state_meta_map.json of that version.
+//
+//go:embed palettes
+var bundles embed.FS
+
+// embeddedBundles is the bundleSet of the palette bundles embedded in the data package.
+var embeddedBundles = newBundleSet(must(fs.Sub(bundles, "palettes")))
+
+// Bundles returns the game versions of all palette bundles embedded in the data package, sorted from oldest to
+// newest.
+func Bundles() []string {
+	return slices.Clone(embeddedBundles.versions())
+}
+
+// BundlePalette returns the Palette of the embedded bundle with the game version passed.
+func BundlePalette(version string) (*Palette, error) {
+	return embeddedBundles.palette(version)
+}
+
+// DefaultPalette returns the Palette of the newest embedded bundle.
+func DefaultPalette() *Palette {
+	return must(embeddedBundles.palette(embeddedBundles.latest()))
+}
+
+// PaletteFor returns the Palette of the embedded bundle that best matches the game version passed, along with the
+// version of that bundle. This is the bundle with the same version or otherwise the newest bundle older than the
+// game version. If all bundles are newer, or the game version is empty or malformed, the newest bundle is
+// returned, which callers should check for by comparing the version of the bundle with ReleaseVersion of the game
+// version.
+func PaletteFor(gameVersion string) (*Palette, string) {
+	version := embeddedBundles.bundleFor(gameVersion)
+	return must(embeddedBundles.palette(version)), version
+}
+
+// bundleFile returns the contents of the file with the name passed in the embedded bundle with the version passed.
+func bundleFile(version, name string) ([]byte, error) {
+	return embeddedBundles.file(version, name)
+}
+
+// bundleSet is a set of palette bundles in a file system, which holds a directory for every bundle named after
+// its game version. Palettes are read from the file system once and cached afterwards.
+type bundleSet struct {
+	fsys fs.FS
+	// versions returns the versions of all bundles in the set, sorted from oldest to newest. Directories that
+	// are not named after a version are ignored.
+	versions func() []string
+
+	mu       sync.Mutex
+	palettes map[string]*Palette
+}
+
+// newBundleSet returns a bundleSet holding the bundles in the fs.FS passed. It panics on first use if the fs.FS
+// does not hold any bundles.
+func newBundleSet(fsys fs.FS) *bundleSet {
+	return &bundleSet{fsys: fsys, palettes: map[string]*Palette{}, versions: sync.OnceValue(func() []string {
+		entries, err := fs.ReadDir(fsys, ".")
+		var versions []string
+		for _, entry := range entries {
+			if _, ok := parseVersion(entry.Name()); ok && entry.IsDir() {
+				versions = append(versions, entry.Name())
+			}
+		}
+		if len(versions) == 0 {
+			panic(fmt.Errorf("no palette bundles found: %v", err))
+		}
+		slices.SortFunc(versions, CompareVersions)
+		return versions
+	})}
+}
+
+// latest returns the version of the newest bundle in the set.
+func (b *bundleSet) latest() string {
+	versions := b.versions()
+	return versions[len(versions)-1]
+}
+
+// bundleFor returns the version of the bundle that best matches the game version passed, as described in
+// PaletteFor. Only the major, minor and patch parts of the game version are taken into account.
+func (b *bundleSet) bundleFor(gameVersion string) string {
+	parts, ok := parseVersion(gameVersion)
+	if !ok {
+		return b.latest()
+	}
+	gameVersion = strings.Join(parts[:min(3, len(parts))], ".")
+	version := b.latest()
+	for _, v := range b.versions() {
+		if CompareVersions(v, gameVersion) <= 0 {
+			version = v
+		}
+	}
+	return version
+}
+
+// palette returns the Palette of the bundle with the version passed.
+func (b *bundleSet) palette(version string) (*Palette, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if p, ok := b.palettes[version]; ok {
+		return p, nil
+	}
+	blockStates, err := b.file(version, "canonical_block_states.nbt")
+	if err != nil {
+		return nil, err
+	}
+	metaMap, err := b.file(version, "block_state_meta_map.json")
+	if err != nil {
+		return nil, err
+	}
+	p, err := ReadPalette(blockStates, metaMap)
+	if err != nil {
+		return nil, fmt.Errorf("palette bundle %s: %w", version, err)
+	}
+	b.palettes[version] = p
+	return p, nil
+}
+
+// file returns the contents of the file with the name passed in the bundle with the version passed.
+func (b *bundleSet) file(version, name string) ([]byte, error) {
+	data, err := fs.ReadFile(b.fsys, path.Join(version, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s of palette bundle %s: %w", name, version, err)
+	}
+	return data, nil
+}
+
+// parseVersion splits a version made up of numbers separated by dots, such as "1.21.60", into its parts. False is
+// returned if the version is empty or any of its parts is not a number.
+func parseVersion(v string) ([]string, bool) {
+	if v == "" {
+		return nil, false
+	}
+	parts := strings.Split(v, ".")
+	for _, p := range parts {
+		if _, err := strconv.ParseUint(p, 10, 32); err != nil {
+			return nil, false
+		}
+	}
+	return parts, true
+}
+
+// ReleaseVersion returns the version of the release that the game version passed belongs to, such as "1.21.60"
+// for "1.21.62.1". Hotfixes increment the patch of the release they fix, which is always a multiple of 10, and do
+// not change the block palette. The game version is returned as is if it is malformed.
+func ReleaseVersion(gameVersion string) string {
+	parts, ok := parseVersion(gameVersion)
+	if !ok || len(parts) < 3 {
+		return gameVersion
+	}
+	patch, _ := strconv.Atoi(parts[2])
+	return fmt.Sprintf("%s.%s.%d", parts[0], parts[1], patch/10*10)
+}
+
+// CompareVersions compares two versions made up of numbers separated by dots, such as "1.21.60", returning -1, 0
+// or 1 if a is older than, equal to or newer than b respectively. Missing parts are treated as 0, so "1.21" equals
+// "1.21.0", and so are parts that are not a number, which should be ruled out using parseVersion.
+func CompareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := range max(len(as), len(bs)) {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// must panics if err is not nil and returns v otherwise.
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
@@ -0,0 +1,154 @@
+package data
+
+import (
+	"io/fs"
+	"strconv"
+	"testing"
+	"testing/fstest"
+
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
+)
+
+// testBundles returns a bundleSet with a bundle for every version passed, each holding a single state with the
+// block version set to the version of the bundle.
+func testBundles(t *testing.T, versions ...string) *bundleSet {
+	t.Helper()
+	fsys := fstest.MapFS{"README.md": {Data: []byte("not a bundle")}, "latest": {Mode: fs.ModeDir | 0755}}
+	for _, v := range versions {
+		parts, _ := parseVersion(v)
+		var blockVersion int32
+		for i, part := range parts {
+			n, _ := strconv.Atoi(part)
+			blockVersion |= int32(n) << (24 - i*8)
+		}
+		state, err := nbt.Marshal(map[string]any{"name": "minecraft:air", "states": map[string]any{}, "version": blockVersion})
+		if err != nil {
+			t.Fatal(err)
+		}
+		fsys[v+"/canonical_block_states.nbt"] = &fstest.MapFile{Data: state}
+		fsys[v+"/block_state_meta_map.json"] = &fstest.MapFile{Data: []byte("[0]")}
+	}
+	return newBundleSet(fsys)
+}
+
+func TestBundleSetVersions(t *testing.T) {
+	b := testBundles(t, "1.21.60", "1.20.80", "1.21.2", "1.21.10")
+	want := []string{"1.20.80", "1.21.2", "1.21.10", "1.21.60"}
+	got := b.versions()
+	if len(got) != len(want) {
+		t.Fatalf("got versions %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got versions %v, want %v", got, want)
+		}
+	}
+}
+
+func TestBundleFor(t *testing.T) {
+	b := testBundles(t, "1.20.80", "1.21.0", "1.21.60")
+	tests := []struct {
+		gameVersion, want string
+	}{
+		// Exact matches, ignoring the revision of the game version.
+		{"1.21.0", "1.21.0"},
+		{"1.21.60", "1.21.60"},
+		{"1.21.60.33", "1.21.60"},
+		// The newest bundle older than the game version, such as for hotfixes.
+		{"1.21.62", "1.21.60"},
+		{"1.21.51.2", "1.21.0"},
+		{"1.21", "1.21.0"},
+		// Game versions newer than every bundle.
+		{"1.22.0", "1.21.60"},
+		{"2.0.0", "1.21.60"},
+		// Game versions older than every bundle get the newest bundle rather than the oldest, which is newer
+		// than the game version either way.
+		{"1.20.0", "1.21.60"},
+		{"0.1.0", "1.21.60"},
+		// Empty and malformed game versions.
+		{"", "1.21.60"},
+		{"1.21.x", "1.21.60"},
+		{"v1.21.0", "1.21.60"},
+		{"1..0", "1.21.60"},
+		{"1.21.0-beta", "1.21.60"},
+	}
+	for _, tt := range tests {
+		if got := b.bundleFor(tt.gameVersion); got != tt.want {
+			t.Errorf("bundleFor(%q) = %s, want %s", tt.gameVersion, got, tt.want)
+		}
+	}
+}
+
+func TestBundleSetPalette(t *testing.T) {
+	b := testBundles(t, "1.20.80", "1.21.60")
+	p, err := b.palette("1.20.80")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Version() != "1.20.80.0" {
+		t.Errorf("got palette version %s, want 1.20.80.0", p.Version())
+	}
+	if cached, _ := b.palette("1.20.80"); cached != p {
+		t.Error("palette of bundle was not cached")
+	}
+	if _, err := b.palette("1.19.0"); err == nil {
+		t.Error("expected error for missing bundle")
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.21.60", "1.21.60", 0},
+		{"1.21.60", "1.21.61", -1},
+		{"1.21.61", "1.21.60", 1},
+		{"1.21.2", "1.21.10", -1},
+		{"1.21.100", "1.21.60", 1},
+		{"1.21", "1.21.0", 0},
+		{"1.21.60.33", "1.21.60", 1},
+		{"1.22", "1.21.60", 1},
+		{"2.0.0", "1.99.99", 1},
+		// Parts that are not a number are treated as 0.
+		{"1.x.0", "1.0.0", 0},
+		{"", "0", 0},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestReleaseVersion(t *testing.T) {
+	tests := []struct {
+		gameVersion, want string
+	}{
+		{"1.21.60", "1.21.60"},
+		{"1.21.62", "1.21.60"},
+		{"1.21.62.1", "1.21.60"},
+		{"1.21.2", "1.21.0"},
+		{"1.21.100", "1.21.100"},
+		{"1.21.131", "1.21.130"},
+		{"1.21", "1.21"},
+		{"", ""},
+		{"1.21.x", "1.21.x"},
+	}
+	for _, tt := range tests {
+		if got := ReleaseVersion(tt.gameVersion); got != tt.want {
+			t.Errorf("ReleaseVersion(%q) = %q, want %q", tt.gameVersion, got, tt.want)
+		}
+	}
+}
+
+func TestPaletteForEmbedded(t *testing.T) {
+	latest := Bundles()[len(Bundles())-1]
+	p, version := PaletteFor("")
+	if version != latest || p != DefaultPalette() {
+		t.Errorf("got bundle %s for empty game version, want %s", version, latest)
+	}
+	if _, version := PaletteFor(latest + ".1"); version != latest {
+		t.Errorf("got bundle %s for game version %s.1, want %s", version, latest, latest)
+	}
+}
@@ -2,51 +2,112 @@ package data
 
 import (
 	"bytes"
-	_ "embed"
 	"encoding/json"
 	"fmt"
-	"github.com/sandertv/gophertunnel/minecraft/nbt"
-)
+	"maps"
+	"os"
+	"slices"
 
-var (
-	//go:embed canonical_block_states.nbt
-	blockPaletteData []byte
-	//go:embed block_state_meta_map.json
-	metaMapData []byte
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
 )
 
-var (
-	ItemNameToNetworkID = make(map[string]int32)
-	ItemNetworkIDToName = make(map[int32]string)
-
-	ItemMetaToBlockState = make(map[string]map[int32]map[string]any)
-)
+// Palette holds the vanilla block states and the item meta values that map to them, as read from
+// canonical_block_states.nbt and block_state_meta_map.json.
+type Palette struct {
+	// states holds all vanilla block states in the order of canonical_block_states.nbt, which is sorted by the
+	// FNV-1 64 hash of the block names, so that the index of a state is its runtime ID.
+	states []BlockState
+	// metaToState maps item names and meta values to the block states they place.
+	metaToState map[string]map[int32]map[string]any
+	// version is the highest block version of the states.
+	version int32
+}
 
-func init() {
-	var metaMap []int32
-	err := json.Unmarshal(metaMapData, &metaMap)
+// LoadPalette loads a Palette from the canonical_block_states.nbt and block_state_meta_map.json files at the paths
+// passed. If a path is empty, the file of the latest bundle embedded in the data package is used instead.
+func LoadPalette(blockStatesPath, metaMapPath string) (*Palette, error) {
+	latest := embeddedBundles.latest()
+	blockStates, err := bundleFile(latest, "canonical_block_states.nbt")
+	if err != nil {
+		return nil, err
+	}
+	metaMap, err := bundleFile(latest, "block_state_meta_map.json")
 	if err != nil {
-		panic(fmt.Errorf("failed to unmarshal block_state_meta_map.json: %w", err))
+		return nil, err
+	}
+	if blockStatesPath != "" {
+		b, err := os.ReadFile(blockStatesPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read block states: %w", err)
+		}
+		blockStates = b
+	}
+	if metaMapPath != "" {
+		b, err := os.ReadFile(metaMapPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read meta map: %w", err)
+		}
+		metaMap = b
 	}
+	return ReadPalette(blockStates, metaMap)
+}
+
+// ReadPalette reads a Palette from the contents of canonical_block_states.nbt and block_state_meta_map.json.
+func ReadPalette(blockStates, metaMap []byte) (*Palette, error) {
+	var metas []int32
+	if err := json.Unmarshal(metaMap, &metas); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal block_state_meta_map.json: %w", err)
+	}
+	p := &Palette{metaToState: make(map[string]map[int32]map[string]any)}
 
-	buf := bytes.NewBuffer(blockPaletteData)
+	buf := bytes.NewBuffer(blockStates)
 	decoder := nbt.NewDecoder(buf)
-	var i int
 	for buf.Len() > 0 {
 		var state map[string]any
-		err = decoder.Decode(&state)
-		if err != nil {
-			panic(fmt.Errorf("failed to unmarshal canonical_block_states.nbt: %w", err))
-		} else if i >= len(metaMap) {
-			panic(fmt.Errorf("meta map does not contain meta value for state: %v", state))
+		if err := decoder.Decode(&state); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal canonical_block_states.nbt: %w", err)
+		} else if len(p.states) >= len(metas) {
+			return nil, fmt.Errorf("meta map does not contain meta value for state: %v", state)
 		}
-		name := state["name"].(string)
-		meta := metaMap[i]
-		if m, ok := ItemMetaToBlockState[name]; ok {
+		name, _ := state["name"].(string)
+		properties, _ := state["states"].(map[string]any)
+		if version, ok := state["version"].(int32); ok {
+			p.version = max(p.version, version)
+		}
+		meta := metas[len(p.states)]
+		p.states = append(p.states, BlockState{Name: name, Properties: properties})
+		if m, ok := p.metaToState[name]; ok {
 			m[meta] = state
 		} else {
-			ItemMetaToBlockState[name] = map[int32]map[string]any{meta: state}
+			p.metaToState[name] = map[int32]map[string]any{meta: state}
 		}
-		i++
 	}
+	if len(p.states) != len(metas) {
+		return nil, fmt.Errorf("meta map has %d meta values for %d states", len(metas), len(p.states))
+	}
+	return p, nil
+}
+
+// Version returns the highest block version of the states in the Palette, formatted like "1.21.60.33".
+func (p *Palette) Version() string {
+	v := uint32(p.version)
+	return fmt.Sprintf("%d.%d.%d.%d", v>>24, v>>16&0xff, v>>8&0xff, v&0xff)
+}
+
+// States returns all block states in the Palette in the order of canonical_block_states.nbt.
+func (p *Palette) States() []BlockState {
+	return slices.Clone(p.states)
+}
+
+// ItemMetas returns the meta values of the item with the name passed that place a block, sorted in ascending
+// order.
+func (p *Palette) ItemMetas(name string) []int32 {
+	return slices.Sorted(maps.Keys(p.metaToState[name]))
+}
+
+// ItemMetaBlockState returns the block state, holding its name, states and version, that the item with the name
+// and meta value passed places. False is returned if the item does not place a block with that meta value.
+func (p *Palette) ItemMetaBlockState(name string, meta int32) (map[string]any, bool) {
+	state, ok := p.metaToState[name][meta]
+	return state, ok
 }
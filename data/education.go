@@ -0,0 +1,150 @@
+package data
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+var (
+	// educationItems holds the names of items that are only available with education features enabled, other
+	// than the chemistry elements, which are matched by their prefix. Items missing from the list are still
+	// recognised if EducationItems derives them from the session, so the list mostly matters for items that are
+	// neither crafted in chemistry recipes nor listed in an education creative group.
+	educationItems = []string{
+		"minecraft:agent_spawn_egg",
+		"minecraft:allow",
+		"minecraft:balloon",
+		"minecraft:bleach",
+		"minecraft:board",
+		"minecraft:border_block",
+		"minecraft:camera",
+		"minecraft:chalkboard",
+		"minecraft:chemical_heat",
+		"minecraft:chemistry_table",
+		"minecraft:colored_torch_blue",
+		"minecraft:colored_torch_bp",
+		"minecraft:colored_torch_green",
+		"minecraft:colored_torch_purple",
+		"minecraft:colored_torch_red",
+		"minecraft:colored_torch_rg",
+		"minecraft:compound",
+		"minecraft:compound_creator",
+		"minecraft:deny",
+		"minecraft:element_constructor",
+		"minecraft:glow_stick",
+		"minecraft:hard_glass",
+		"minecraft:hard_glass_pane",
+		"minecraft:hard_stained_glass",
+		"minecraft:hard_stained_glass_pane",
+		"minecraft:ice_bomb",
+		"minecraft:lab_table",
+		"minecraft:material_reducer",
+		"minecraft:medicine",
+		"minecraft:npc_spawn_egg",
+		"minecraft:photo",
+		"minecraft:portfolio",
+		"minecraft:rapid_fertilizer",
+		"minecraft:sparkler",
+		"minecraft:underwater_tnt",
+		"minecraft:underwater_torch",
+	}
+	// educationBlocks holds the names of the crafting blocks of recipes that are only available with education
+	// features enabled.
+	educationBlocks = []string{"compound_creator", "element_constructor", "lab_table", "material_reducer"}
+)
+
+// IsEducationItem checks if the item with the name passed is only available with education features enabled.
+func IsEducationItem(name string) bool {
+	if slices.Contains(educationItems, name) {
+		return true
+	}
+	if rest, ok := strings.CutPrefix(name, "minecraft:element_"); ok {
+		// The elements of the periodic table are named element_0 to element_118.
+		return rest != "" && strings.Trim(rest, "0123456789") == ""
+	}
+	// Coloured variants of hard glass are named like minecraft:hard_red_stained_glass.
+	return strings.HasPrefix(name, "minecraft:hard_") && strings.Contains(name, "_stained_glass")
+}
+
+// EducationItems returns the names of the items that the CraftingData and CreativeContent packets passed show to be
+// only available with education features enabled, resolving network IDs using the Registry passed. These are the
+// outputs of chemistry recipes and of recipes crafted in education blocks, and the items in creative groups that
+// have an education word in their name or an education item as icon. Either packet may be nil.
+func EducationItems(reg *Registry, crafting *packet.CraftingData, creative *packet.CreativeContent) []string {
+	education := make(map[string]bool)
+	add := func(stacks ...protocol.ItemStack) {
+		for _, stack := range stacks {
+			if name, ok := reg.ItemName(stack.NetworkID); ok {
+				education[name] = true
+			}
+		}
+	}
+	if crafting != nil {
+		for _, recipe := range crafting.Recipes {
+			switch r := recipe.(type) {
+			case *protocol.ShapedChemistryRecipe:
+				add(r.Output...)
+			case *protocol.ShapelessChemistryRecipe:
+				add(r.Output...)
+			case *protocol.ShapedRecipe:
+				if IsEducationBlock(r.Block) {
+					add(r.Output...)
+				}
+			case *protocol.ShapelessRecipe:
+				if IsEducationBlock(r.Block) {
+					add(r.Output...)
+				}
+			}
+		}
+	}
+	if creative != nil {
+		groups := make([]bool, len(creative.Groups))
+		for i, group := range creative.Groups {
+			icon, _ := reg.ItemName(group.Icon.NetworkID)
+			groups[i] = hasEducationWord(group.Name) || education[icon] || IsEducationItem(icon)
+		}
+		for _, item := range creative.Items {
+			if int(item.GroupIndex) < len(groups) && groups[item.GroupIndex] {
+				add(item.Item)
+			}
+		}
+	}
+	return slices.Sorted(maps.Keys(education))
+}
+
+// IsEducationBlock checks if a recipe crafted in the block with the name passed is only available with education
+// features enabled.
+func IsEducationBlock(block string) bool {
+	return slices.Contains(educationBlocks, strings.TrimPrefix(block, "minecraft:"))
+}
+
+// IsEducationTag checks if the item tag passed marks items that are only available with education features
+// enabled, such as "minecraft:edu_item".
+func IsEducationTag(tag string) bool {
+	return hasEducationWord(tag)
+}
+
+// IsEducationExperiment checks if the experiment, or comma separated list of experiments, passed enables
+// education features, such as the chemistry experiment.
+func IsEducationExperiment(experiment string) bool {
+	for _, name := range strings.Split(experiment, ",") {
+		if hasEducationWord(name) || strings.Contains(name, "chemistry") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasEducationWord checks if one of the words in the identifier passed, separated by colons, underscores or dots,
+// is "edu" or "education".
+func hasEducationWord(s string) bool {
+	return slices.ContainsFunc(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r == ':' || r == '_' || r == '.'
+	}), func(word string) bool {
+		return word == "edu" || word == "education"
+	})
+}
@@ -0,0 +1,145 @@
+package data
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+func TestIsEducationItem(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"minecraft:chemistry_table", true},
+		{"minecraft:compound", true},
+		{"minecraft:element_0", true},
+		{"minecraft:element_118", true},
+		{"minecraft:hard_stained_glass", true},
+		{"minecraft:hard_red_stained_glass_pane", true},
+		{"minecraft:element_", false},
+		{"minecraft:element_constructor", true},
+		{"minecraft:element_x", false},
+		{"minecraft:stained_glass", false},
+		{"minecraft:hard_clay", false},
+		{"minecraft:stone", false},
+		{"custom:compound", false},
+	}
+	for _, tt := range tests {
+		if got := IsEducationItem(tt.name); got != tt.want {
+			t.Errorf("IsEducationItem(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsEducationBlock(t *testing.T) {
+	tests := []struct {
+		block string
+		want  bool
+	}{
+		{"compound_creator", true},
+		{"minecraft:lab_table", true},
+		{"material_reducer", true},
+		{"crafting_table", false},
+		{"furnace", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsEducationBlock(tt.block); got != tt.want {
+			t.Errorf("IsEducationBlock(%q) = %v, want %v", tt.block, got, tt.want)
+		}
+	}
+}
+
+func TestIsEducationTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want bool
+	}{
+		{"minecraft:edu_item", true},
+		{"minecraft:education", true},
+		{"minecraft:EDU.tools", true},
+		{"minecraft:logs", false},
+		// Words merely containing "edu" are not education words.
+		{"minecraft:reduced", false},
+		{"minecraft:educational", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsEducationTag(tt.tag); got != tt.want {
+			t.Errorf("IsEducationTag(%q) = %v, want %v", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestIsEducationExperiment(t *testing.T) {
+	tests := []struct {
+		experiment string
+		want       bool
+	}{
+		{"chemistry", true},
+		{"edu_features", true},
+		{"gametest,education", true},
+		{"gametest,chemistry_experiment", true},
+		{"gametest", false},
+		{"gametest,villager_trades_rebalance", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsEducationExperiment(tt.experiment); got != tt.want {
+			t.Errorf("IsEducationExperiment(%q) = %v, want %v", tt.experiment, got, tt.want)
+		}
+	}
+}
+
+func TestEducationItems(t *testing.T) {
+	names := []string{
+		"minecraft:stone", "minecraft:stick", "minecraft:new_compound", "minecraft:new_lab_item", "minecraft:crafted",
+		"minecraft:grouped", "minecraft:icon_grouped", "minecraft:element_1",
+	}
+	var items []protocol.ItemEntry
+	for i, name := range names {
+		items = append(items, protocol.ItemEntry{Name: name, RuntimeID: int16(i + 1)})
+	}
+	reg := NewRegistry(minecraft.GameData{Items: items}, DefaultPalette())
+	stack := func(name string) protocol.ItemStack {
+		id, _ := reg.ItemNetworkID(name)
+		return protocol.ItemStack{ItemType: protocol.ItemType{NetworkID: id}, Count: 1}
+	}
+	crafting := &packet.CraftingData{Recipes: []protocol.Recipe{
+		&protocol.ShapelessChemistryRecipe{ShapelessRecipe: protocol.ShapelessRecipe{Output: []protocol.ItemStack{stack("minecraft:new_compound")}}},
+		&protocol.ShapedRecipe{Block: "lab_table", Output: []protocol.ItemStack{stack("minecraft:new_lab_item")}},
+		&protocol.ShapedRecipe{Block: "crafting_table", Output: []protocol.ItemStack{stack("minecraft:crafted")}},
+	}}
+	creative := &packet.CreativeContent{
+		Groups: []protocol.CreativeGroup{
+			{Name: "itemGroup.name.stone", Icon: stack("minecraft:stone")},
+			{Name: "itemGroup.name.edu_stuff", Icon: stack("minecraft:stone")},
+			{Name: "itemGroup.name.elements", Icon: stack("minecraft:element_1")},
+		},
+		Items: []protocol.CreativeItem{
+			{Item: stack("minecraft:stick"), GroupIndex: 0},
+			{Item: stack("minecraft:grouped"), GroupIndex: 1},
+			{Item: stack("minecraft:icon_grouped"), GroupIndex: 2},
+			{Item: stack("minecraft:stone"), GroupIndex: 5},
+		},
+	}
+	want := []string{"minecraft:grouped", "minecraft:icon_grouped", "minecraft:new_compound", "minecraft:new_lab_item"}
+	if got := EducationItems(reg, crafting, creative); !slices.Equal(got, want) {
+		t.Errorf("got education items %v, want %v", got, want)
+	}
+	if got := EducationItems(reg, nil, nil); len(got) != 0 {
+		t.Errorf("got education items %v without packets, want none", got)
+	}
+
+	withEducation := reg.WithEducationItems(want)
+	if !withEducation.IsEducationItem("minecraft:new_compound") || !withEducation.IsEducationItem("minecraft:compound") {
+		t.Error("Registry with education items does not report them or the built-in education items")
+	}
+	if reg.IsEducationItem("minecraft:new_compound") || withEducation.IsEducationItem("minecraft:stick") {
+		t.Error("Registry reports items that are not education items as such")
+	}
+}
@@ -0,0 +1,108 @@
+package data
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"slices"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+// BlockState is a single block state in the block palette, holding the name of the block and the values of its
+// properties.
+type BlockState struct {
+	Name       string
+	Properties map[string]any
+}
+
+// CustomBlockStates returns all states of the custom block passed: one state for every combination of the
+// values of its properties, with the values of the last property changing fastest.
+func CustomBlockStates(block protocol.BlockEntry) []map[string]any {
+	blockStates := []map[string]any{{}}
+	properties, _ := block.Properties["properties"].([]any)
+	for _, p := range properties {
+		property, ok := p.(map[string]any)
+		if !ok {
+			panic(fmt.Errorf("custom block %s has property of unexpected type %T", block.Name, p))
+		}
+		name, _ := property["name"].(string)
+		values, _ := property["enum"].([]any)
+		if name == "" || len(values) == 0 {
+			panic(fmt.Errorf("custom block %s has invalid property %v", block.Name, property))
+		}
+		next := make([]map[string]any, 0, len(blockStates)*len(values))
+		for _, s := range blockStates {
+			for _, value := range values {
+				state := maps.Clone(s)
+				state[name] = value
+				next = append(next, state)
+			}
+		}
+		blockStates = next
+	}
+	return blockStates
+}
+
+// nameHash returns the FNV-1 64 hash of the block name passed, by which the block palette is sorted.
+func nameHash(name string) uint64 {
+	h := fnv.New64()
+	_, _ = h.Write([]byte(name))
+	return h.Sum64()
+}
+
+// Tag types of the NBT encoding of block states hashed by NetworkHash.
+const (
+	tagEnd      = 0
+	tagByte     = 1
+	tagInt      = 3
+	tagString   = 8
+	tagCompound = 10
+)
+
+// NetworkHash returns the hash that the server uses as network ID of the block state with the name and properties
+// passed if block-network-ids-are-hashes is enabled. It is the FNV-1a 32 hash of the little-endian NBT encoding of
+// the name and the properties, sorted by their name. The minecraft:unknown block always has the hash -2.
+func NetworkHash(name string, properties map[string]any) uint32 {
+	if name == "minecraft:unknown" {
+		return 0xfffffffe
+	}
+	var buf bytes.Buffer
+	writeString := func(s string) {
+		_ = binary.Write(&buf, binary.LittleEndian, uint16(len(s)))
+		buf.WriteString(s)
+	}
+	buf.WriteByte(tagCompound)
+	writeString("")
+	buf.WriteByte(tagString)
+	writeString("name")
+	writeString(name)
+	buf.WriteByte(tagCompound)
+	writeString("states")
+	for _, k := range slices.Sorted(maps.Keys(properties)) {
+		switch v := properties[k].(type) {
+		case uint8:
+			buf.WriteByte(tagByte)
+			writeString(k)
+			buf.WriteByte(v)
+		case int32:
+			buf.WriteByte(tagInt)
+			writeString(k)
+			_ = binary.Write(&buf, binary.LittleEndian, v)
+		case string:
+			buf.WriteByte(tagString)
+			writeString(k)
+			writeString(v)
+		default:
+			panic(fmt.Errorf("block %s has property %s of unexpected type %T", name, k, v))
+		}
+	}
+	buf.WriteByte(tagEnd)
+	buf.WriteByte(tagEnd)
+
+	h := fnv.New32a()
+	_, _ = h.Write(buf.Bytes())
+	return h.Sum32()
+}
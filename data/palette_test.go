@@ -0,0 +1,33 @@
+package data
+
+import "testing"
+
+func TestNetworkHash(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties map[string]any
+		want       int32
+	}{
+		{"minecraft:air", nil, -604749536},
+		{"minecraft:air", map[string]any{}, -604749536},
+		// The hash of minecraft:unknown is fixed, whatever its properties.
+		{"minecraft:unknown", nil, -2},
+		{"minecraft:unknown", map[string]any{"foo": int32(1)}, -2},
+	}
+	for _, tt := range tests {
+		if got := int32(NetworkHash(tt.name, tt.properties)); got != tt.want {
+			t.Errorf("NetworkHash(%s, %v) = %d, want %d", tt.name, tt.properties, got, tt.want)
+		}
+	}
+}
+
+func TestNetworkHashProperties(t *testing.T) {
+	a := NetworkHash("minecraft:wool", map[string]any{"color": "red", "lit": uint8(1), "age": int32(2)})
+	b := NetworkHash("minecraft:wool", map[string]any{"age": int32(2), "lit": uint8(1), "color": "red"})
+	if a != b {
+		t.Errorf("hash depends on the order of properties: %d != %d", a, b)
+	}
+	if c := NetworkHash("minecraft:wool", map[string]any{"color": "red", "lit": uint8(1), "age": int32(3)}); a == c {
+		t.Errorf("hash does not depend on property values: %d", a)
+	}
+}
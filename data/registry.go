@@ -0,0 +1,114 @@
+package data
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/sandertv/gophertunnel/minecraft"
+)
+
+// Registry holds the items and block states of a single session. It is created from the game data of the
+// session and a Palette, and is read-only once created, so that it may be shared between generators.
+type Registry struct {
+	itemNames   map[int32]string
+	itemIDs     map[string]int32
+	states      []BlockState
+	metaToState map[string]map[int32]map[string]any
+	// education holds the items derived from the session to be only available with education features enabled.
+	education map[string]bool
+}
+
+// NewRegistry creates a Registry from the game data and palette passed. The states of the custom blocks in the
+// game data are added to the vanilla states of the palette and, like the server does, all states are sorted by
+// the FNV-1 64 hash of their block name, so runtime IDs of vanilla blocks shift if custom blocks are present.
+func NewRegistry(gameData minecraft.GameData, palette *Palette) *Registry {
+	r := &Registry{
+		itemNames:   make(map[int32]string, len(gameData.Items)),
+		itemIDs:     make(map[string]int32, len(gameData.Items)),
+		states:      slices.Clone(palette.states),
+		metaToState: palette.metaToState,
+	}
+	for _, item := range gameData.Items {
+		r.itemNames[int32(item.RuntimeID)] = item.Name
+		r.itemIDs[item.Name] = int32(item.RuntimeID)
+	}
+	for _, block := range gameData.CustomBlocks {
+		if strings.HasPrefix(block.Name, "minecraft:") {
+			panic(fmt.Errorf("custom block %s uses the minecraft namespace", block.Name))
+		}
+		for _, properties := range CustomBlockStates(block) {
+			r.states = append(r.states, BlockState{Name: block.Name, Properties: properties})
+		}
+	}
+	if len(gameData.CustomBlocks) > 0 {
+		slices.SortStableFunc(r.states, func(a, b BlockState) int {
+			return cmp.Compare(nameHash(a.Name), nameHash(b.Name))
+		})
+	}
+	return r
+}
+
+// ItemName returns the name of the item with the network ID passed. False is returned if no such item exists.
+func (r *Registry) ItemName(networkID int32) (string, bool) {
+	name, ok := r.itemNames[networkID]
+	return name, ok
+}
+
+// ItemNetworkID returns the network ID of the item with the name passed. False is returned if no such item
+// exists.
+func (r *Registry) ItemNetworkID(name string) (int32, bool) {
+	id, ok := r.itemIDs[name]
+	return id, ok
+}
+
+// BlockState returns the name and properties of the block state with the runtime ID passed. False is returned if
+// no block state with the runtime ID exists.
+func (r *Registry) BlockState(runtimeID uint32) (string, map[string]any, bool) {
+	if runtimeID >= uint32(len(r.states)) {
+		return "", nil, false
+	}
+	s := r.states[runtimeID]
+	return s.Name, s.Properties, true
+}
+
+// ItemMetaBlockState returns the block state, holding its name, states and version, that the item with the name
+// and meta value passed places. False is returned if the item does not place a block with that meta value.
+func (r *Registry) ItemMetaBlockState(name string, meta int32) (map[string]any, bool) {
+	state, ok := r.metaToState[name][meta]
+	return state, ok
+}
+
+// States returns all block states of the Registry, including those of custom blocks, in the order of their runtime
+// IDs.
+func (r *Registry) States() []BlockState {
+	return slices.Clone(r.states)
+}
+
+// ItemMetaBlockStates returns the block states placed by items with specific meta values, indexed by the item name
+// and the meta value. The maps returned must not be modified.
+func (r *Registry) ItemMetaBlockStates() map[string]map[int32]map[string]any {
+	return maps.Clone(r.metaToState)
+}
+
+// WithEducationItems returns a copy of the Registry that also reports the items passed, such as those returned by
+// EducationItems, as only available with education features enabled.
+func (r *Registry) WithEducationItems(names []string) *Registry {
+	c := *r
+	c.education = maps.Clone(r.education)
+	if c.education == nil {
+		c.education = make(map[string]bool, len(names))
+	}
+	for _, name := range names {
+		c.education[name] = true
+	}
+	return &c
+}
+
+// IsEducationItem checks if the item with the name passed is only available with education features enabled,
+// either because it was passed to WithEducationItems or because IsEducationItem reports so.
+func (r *Registry) IsEducationItem(name string) bool {
+	return r.education[name] || IsEducationItem(name)
+}
@@ -0,0 +1,237 @@
+// Package datagen generates data for dragonfly and PocketMine from sessions captured from a vanilla server. The
+// data is generated in memory by Generate and written to a directory separately by Result.Write, so that other
+// tools may use the data directly.
+package datagen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/df-mc/datagen/data"
+	"github.com/df-mc/datagen/dragonfly"
+	"github.com/df-mc/datagen/pocketmine"
+	"github.com/df-mc/datagen/session"
+	"github.com/df-mc/datagen/write"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// Options holds the options used to generate data.
+type Options struct {
+	// Palette is the palette used to resolve block states for all sessions. If nil, the embedded palette bundle
+	// matching the game version of each session is used.
+	Palette *data.Palette
+	// AllowPacks allows generating data from sessions with behaviour or resource packs active. By default, an
+	// error is returned for such sessions, as their data is not vanilla.
+	AllowPacks bool
+}
+
+// Result holds the data generated from the sessions of a Source.
+type Result struct {
+	// Sessions holds the sessions that the data was generated from, sorted by the number of experiments
+	// enabled. The first session is the base session.
+	Sessions []*session.Session
+	// Bundles holds the version of the palette bundle used for every session, in the same order as Sessions.
+	// The versions are empty if the palette was passed in the Options.
+	Bundles []string
+	// Dragonfly holds the dragonfly data of the base session, with the data of the other sessions merged into
+	// it and tagged with the experiments that introduced it.
+	Dragonfly *dragonfly.Output
+	// PocketMine holds the PocketMine data of the base session.
+	PocketMine *pocketmine.Output
+	// Manifest holds information about the sessions, such as their game versions, experiments and packs.
+	Manifest session.Manifest
+	// Warnings holds the problems found while generating the data that did not prevent generating it, such as
+	// palettes that may be outdated.
+	Warnings []string
+}
+
+// Generate captures the sessions of the Source passed and generates the data of all of them. The session with
+// the fewest experiments enabled is used as the base, with the data of the other sessions merged into it and
+// tagged with the experiments that introduced it. Nothing is written until Result.Write is called, and problems
+// that do not prevent generating the data are returned as the Warnings of the Result rather than printed.
+func Generate(ctx context.Context, src Source, opts Options) (res *Result, err error) {
+	sessions, err := src.Sessions(ctx)
+	if err != nil {
+		return nil, err
+	} else if len(sessions) == 0 {
+		return nil, errors.New("source has no sessions")
+	}
+	// The generators panic on data they cannot convert, which is returned as an error here instead.
+	defer func() {
+		if r := recover(); r != nil {
+			res, err = nil, fmt.Errorf("generate data: %v", r)
+		}
+	}()
+
+	for _, s := range sessions {
+		if packs := s.Packs(); len(packs) > 0 && !opts.AllowPacks {
+			return nil, fmt.Errorf("server has %d behaviour or resource packs active, so its data is not vanilla: allow packs to generate it anyway", len(packs))
+		}
+	}
+	sessions = slices.Clone(sessions)
+	slices.SortStableFunc(sessions, func(a, b *session.Session) int {
+		return len(a.Experiments()) - len(b.Experiments())
+	})
+	res = &Result{Sessions: sessions, Manifest: session.NewManifest(sessions)}
+
+	registries := make([]*data.Registry, len(sessions))
+	for i, s := range sessions {
+		palette, bundle := opts.Palette, ""
+		if palette == nil {
+			palette, bundle = data.PaletteFor(s.GameVersion())
+		}
+		warnings := unseenPackets(s)
+		if warning := checkPaletteVersion(palette, bundle, s); warning != "" {
+			warnings = append(warnings, warning)
+		}
+		for _, w := range warnings {
+			if !slices.Contains(res.Warnings, w) {
+				res.Warnings = append(res.Warnings, w)
+			}
+		}
+		res.Bundles = append(res.Bundles, bundle)
+		registries[i] = newRegistry(s, palette)
+	}
+
+	base := sessions[0]
+	res.Dragonfly = dragonflyOutput(base, registries[0])
+	for i, s := range sessions[1:] {
+		res.Dragonfly.Merge(dragonflyOutput(s, registries[i+1]), experimentTag(base, s))
+	}
+	res.PocketMine = pocketmineOutput(base, registries[0])
+	for _, w := range slices.Concat(res.Dragonfly.Warnings, res.PocketMine.Warnings) {
+		if !slices.Contains(res.Warnings, w) {
+			res.Warnings = append(res.Warnings, w)
+		}
+	}
+	return res, nil
+}
+
+// Write writes all data of the Result to the directory passed within the FS passed, with the dragonfly and
+// PocketMine data in the dragonfly and pocketmine directories respectively.
+func (r *Result) Write(fsys write.FS, dir string) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("write data: %v", rec)
+		}
+	}()
+	r.Dragonfly.Write(write.Sub(fsys, filepath.Join(dir, "dragonfly")))
+	r.PocketMine.Write(write.Sub(fsys, filepath.Join(dir, "pocketmine")))
+	write.JSON(fsys, filepath.Join(dir, "manifest.json"), r.Manifest)
+	return nil
+}
+
+// checkPaletteVersion returns a warning if the palette passed may not match the version of the game that the
+// server of the session passed was running, or an empty string otherwise. The palette is compared by the version
+// of the palette bundle chosen by PaletteFor, which is passed, or by its block version if it was passed explicitly,
+// for which the bundle is empty. It may be outdated if the server runs a newer release, as hotfixes do not change
+// the block palette, and may not match if the server runs an older release, such as when every embedded bundle is
+// newer than the server.
+func checkPaletteVersion(palette *data.Palette, bundle string, s *session.Session) string {
+	version := s.GameVersion()
+	if version == "" {
+		return "could not check palette version: server did not send its game version"
+	}
+	name, paletteVersion := "palette bundle "+bundle, bundle
+	if bundle == "" {
+		name, paletteVersion = "palette block version "+palette.Version(), data.ReleaseVersion(palette.Version())
+	}
+	switch data.CompareVersions(paletteVersion, data.ReleaseVersion(version)) {
+	case -1:
+		return fmt.Sprintf("%s is older than server version %s, palette files may be outdated", name, version)
+	case 1:
+		return fmt.Sprintf("%s is newer than server version %s, palette files may not match", name, version)
+	}
+	return ""
+}
+
+// newRegistry creates the Registry of the session passed using the palette passed, reporting the items that the
+// crafting data and creative content of the session show to be education items as such.
+func newRegistry(s *session.Session, palette *data.Palette) *data.Registry {
+	reg := data.NewRegistry(s.GameData, palette)
+	crafting, _ := session.Packet[*packet.CraftingData](s)
+	creative, _ := session.Packet[*packet.CreativeContent](s)
+	return reg.WithEducationItems(data.EducationItems(reg, crafting, creative))
+}
+
+// dragonflyOutput generates the dragonfly data for a single session, resolving items and block states using the
+// Registry of the session passed.
+func dragonflyOutput(s *session.Session, reg *data.Registry) *dragonfly.Output {
+	o := dragonfly.NewOutput(reg)
+	o.HandleGameData(s.GameData)
+	for _, id := range handledPackets {
+		switch p := s.Packets[id].(type) {
+		case *packet.AvailableActorIdentifiers:
+			o.HandleAvailableActorIdentifiers(p)
+		case *packet.AvailableCommands:
+			o.HandleAvailableCommands(p)
+		case *packet.BiomeDefinitionList:
+			o.HandleBiomeDefinitionList(p)
+		case *packet.CameraAimAssistPresets:
+			o.HandleCameraAimAssistPresets(p)
+		case *packet.CameraPresets:
+			o.HandleCameraPresets(p)
+		case *packet.CraftingData:
+			o.HandleCraftingData(p)
+		case *packet.CreativeContent:
+			o.HandleCreativeContent(p)
+		case *packet.DimensionData:
+			o.HandleDimensionData(p)
+		case *packet.FeatureRegistry:
+			o.HandleFeatureRegistry(p)
+		case *packet.JigsawStructureData:
+			o.HandleJigsawStructureData(p)
+		case *packet.TrimData:
+			o.HandleTrimData(p)
+		case *packet.UnlockedRecipes:
+			o.HandleUnlockedRecipes(p)
+		}
+	}
+	for _, id := range repeatedPackets {
+		for _, pk := range s.Repeated[id] {
+			switch p := pk.(type) {
+			case *packet.SyncActorProperty:
+				o.HandleSyncActorProperty(p)
+			}
+		}
+	}
+	return o
+}
+
+// pocketmineOutput generates the PocketMine data for a single session, resolving items and block states using the
+// Registry of the session passed.
+func pocketmineOutput(s *session.Session, reg *data.Registry) *pocketmine.Output {
+	o := pocketmine.NewOutput(reg)
+	o.HandleGameData(s.GameData)
+	for _, id := range handledPackets {
+		switch p := s.Packets[id].(type) {
+		case *packet.AvailableActorIdentifiers:
+			o.HandleAvailableActorIdentifiers(p)
+		case *packet.BiomeDefinitionList:
+			o.HandleBiomeDefinitionList(p)
+		case *packet.CraftingData:
+			o.HandleCraftingData(p)
+		case *packet.CreativeContent:
+			o.HandleCreativeContent(p)
+		case *packet.TrimData:
+			o.HandleTrimData(p)
+		}
+	}
+	return o
+}
+
+// experimentTag returns the tag for data introduced in session s compared to the base session: the experiments
+// enabled in s but not in base, separated by commas.
+func experimentTag(base, s *session.Session) string {
+	var tag []string
+	for _, name := range s.Experiments() {
+		if !slices.Contains(base.Experiments(), name) {
+			tag = append(tag, name)
+		}
+	}
+	return strings.Join(tag, ",")
+}
@@ -0,0 +1,102 @@
+package datagen
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/df-mc/datagen/data"
+	"github.com/df-mc/datagen/session"
+	"github.com/df-mc/datagen/write"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+func TestCheckPaletteVersion(t *testing.T) {
+	state, err := nbt.Marshal(map[string]any{"name": "minecraft:air", "states": map[string]any{}, "version": int32(1<<24 | 21<<16 | 50<<8)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, err := data.ReadPalette(state, []byte("[0]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := data.Bundles()[len(data.Bundles())-1]
+
+	tests := []struct {
+		name        string
+		palette     *data.Palette
+		bundle      string
+		gameVersion string
+		warn        bool
+	}{
+		{"bundle_exact", data.DefaultPalette(), latest, latest, false},
+		{"bundle_revision", data.DefaultPalette(), latest, latest + ".33", false},
+		{"bundle_hotfix", data.DefaultPalette(), latest, strings.TrimSuffix(latest, "0") + "2", false},
+		{"bundle_outdated", data.DefaultPalette(), latest, "99.0.0", true},
+		{"bundle_newer", data.DefaultPalette(), latest, "1.0.0", true},
+		{"explicit_exact", data.DefaultPalette(), "", latest, false},
+		{"explicit_hotfix", old, "", "1.21.51.2", false},
+		{"explicit_outdated", old, "", latest, true},
+		{"explicit_newer", data.DefaultPalette(), "", "1.21.0", true},
+		{"no_game_version", data.DefaultPalette(), latest, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := session.New(minecraft.GameData{})
+			if tt.gameVersion != "" {
+				s.Add(&packet.StartGame{GameVersion: tt.gameVersion})
+			}
+			if warning := checkPaletteVersion(tt.palette, tt.bundle, s); (warning != "") != tt.warn {
+				t.Errorf("got warning %q, expected warning: %v", warning, tt.warn)
+			}
+		})
+	}
+}
+
+func TestGenerateWrite(t *testing.T) {
+	res, err := Generate(context.Background(), Sessions{session.New(minecraft.GameData{})}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Bundles) != 1 || res.Bundles[0] != data.Bundles()[len(data.Bundles())-1] {
+		t.Errorf("got bundles %v, want the newest bundle for a session without game version", res.Bundles)
+	}
+	if !slices.Contains(res.Warnings, "could not check palette version: server did not send its game version") {
+		t.Errorf("palette version warning not returned: %q", res.Warnings)
+	}
+
+	dir := filepath.Join(t.TempDir(), "output")
+	mem := write.NewMemFS()
+	if err := res.Write(mem, dir); err != nil {
+		t.Fatal(err)
+	}
+	diff, err := mem.Diff(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"manifest.json", "dragonfly/game_settings.json", "pocketmine/required_item_list.json"} {
+		if !slices.Contains(diff.Created, filepath.Join(dir, path)) {
+			t.Errorf("%s not written to %s: %v", path, dir, diff.Created)
+		}
+	}
+}
+
+func TestUnseenPackets(t *testing.T) {
+	s := session.New(minecraft.GameData{})
+	s.Add(&packet.DimensionData{})
+	s.AddRepeated(&packet.SyncActorProperty{})
+	want := []string{
+		"server did not send a CameraAimAssistPresets packet, its data is missing",
+		"server did not send a UnlockedRecipes packet, its data is missing",
+	}
+	if got := unseenPackets(s); !slices.Equal(got, want) {
+		t.Errorf("got warnings %q, want %q", got, want)
+	}
+	if got := unseenPackets(session.New(minecraft.GameData{})); len(got) != len(optionalPackets)+len(repeatedPackets) {
+		t.Errorf("got warnings %q for empty session, want one per optional and repeated packet", got)
+	}
+}
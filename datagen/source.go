@@ -0,0 +1,290 @@
+package datagen
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/df-mc/datagen/bds"
+	"github.com/df-mc/datagen/session"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"golang.org/x/oauth2"
+)
+
+// Source provides the sessions that data is generated from.
+type Source interface {
+	// Sessions returns the sessions to generate data from. Cancelling the context closes any connections to
+	// servers, after which the packets received so far are used if they include all packets that are not
+	// optional, and an error is returned otherwise.
+	Sessions(ctx context.Context) ([]*session.Session, error)
+}
+
+// Servers is a Source that connects to running servers, capturing one session per address.
+type Servers struct {
+	// Addrs holds the addresses of the servers to connect to.
+	Addrs []string
+	// TokenSource is the token source used to authenticate with Xbox Live.
+	TokenSource oauth2.TokenSource
+}
+
+// Sessions captures a session from every server in Addrs.
+func (s Servers) Sessions(ctx context.Context) ([]*session.Session, error) {
+	var sessions []*session.Session
+	for _, addr := range s.Addrs {
+		sess, err := Capture(ctx, s.TokenSource, addr)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, sess)
+	}
+	return sessions, nil
+}
+
+// BDS is a Source that configures and starts a BDS installation once for every set of experiments, capturing a
+// session from each run and shutting the server down again afterwards.
+type BDS struct {
+	// Dir is the directory of the BDS installation.
+	Dir string
+	// Education specifies if education features are enabled in the worlds of the server.
+	Education bool
+	// Experiments holds the sets of experiments to enable, one world and session per set. If empty, a single
+	// session without experiments is captured.
+	Experiments [][]string
+	// TokenSource is the token source used to authenticate with Xbox Live.
+	TokenSource oauth2.TokenSource
+	// Log is the writer that the output of the server is written to. It may be nil.
+	Log io.Writer
+}
+
+// Sessions runs the server for every set of experiments and captures a session from it.
+func (b BDS) Sessions(ctx context.Context) ([]*session.Session, error) {
+	sets := b.Experiments
+	if len(sets) == 0 {
+		sets = [][]string{nil}
+	}
+	var sessions []*session.Session
+	for _, set := range sets {
+		server := bds.Server{
+			Dir:         b.Dir,
+			LevelName:   strings.Join(append([]string{"datagen"}, set...), "_"),
+			Education:   b.Education,
+			Experiments: set,
+			Log:         b.Log,
+		}
+		err := server.Run(context.WithoutCancel(ctx), func(addr string) error {
+			sess, err := Capture(ctx, b.TokenSource, addr)
+			if err != nil {
+				return err
+			}
+			sessions = append(sessions, sess)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sessions, nil
+}
+
+// Sessions is a Source holding sessions that were already captured.
+type Sessions []*session.Session
+
+// Sessions returns the sessions held.
+func (s Sessions) Sessions(context.Context) ([]*session.Session, error) {
+	return s, nil
+}
+
+// handledPackets holds the IDs of all packets that data is generated from.
+var handledPackets = []uint32{
+	packet.IDAvailableActorIdentifiers,
+	packet.IDAvailableCommands,
+	packet.IDBiomeDefinitionList,
+	packet.IDCameraAimAssistPresets,
+	packet.IDCameraPresets,
+	packet.IDCraftingData,
+	packet.IDCreativeContent,
+	packet.IDDimensionData,
+	packet.IDFeatureRegistry,
+	packet.IDJigsawStructureData,
+	packet.IDTrimData,
+	packet.IDUnlockedRecipes,
+}
+
+// optionalPackets holds the IDs of handled packets that the server does not always send, such as those only sent
+// with specific experiments or settings. They are stored if they are received before the capture ends, but never
+// waited for.
+var optionalPackets = []uint32{
+	packet.IDCameraAimAssistPresets,
+	packet.IDDimensionData,
+	packet.IDUnlockedRecipes,
+}
+
+// captureTimeout is the maximum duration to wait for the server to send all handled packets that are not optional
+// after connecting to it.
+const captureTimeout = time.Minute
+
+// captureQuietPeriod is the duration to keep reading packets after all handled packets that are not optional were
+// received, so that optional and repeated packets sent shortly after them are not lost. The period restarts
+// whenever another optional or repeated packet is received.
+const captureQuietPeriod = time.Second * 5
+
+// repeatedPackets holds the IDs of packets that data is generated from that the server sends more than once. All
+// of them are stored if they are received before the capture ends, but never waited for.
+var repeatedPackets = []uint32{
+	packet.IDSyncActorProperty,
+}
+
+// Capture connects to the server at the address passed and collects the game data and the packets it sends
+// until all packets that are not optional have been received and no optional or repeated packet followed for the
+// quiet period, or until the connection is closed, either by the server or by cancelling the context. An error is
+// returned if any packet that is not optional was not received by then or within the capture timeout.
+func Capture(ctx context.Context, src oauth2.TokenSource, addr string) (*session.Session, error) {
+	// The packets describing the pack stack and the StartGame packet are handled by the connection during login
+	// and never returned by ReadPacket, so they are decoded from the raw packets instead.
+	var mu sync.Mutex
+	var packs []packet.Packet
+	var decodeErr error
+	dialer := minecraft.Dialer{
+		TokenSource: src,
+		PacketFunc: func(header packet.Header, payload []byte, _, _ net.Addr) {
+			// Packets are decoded with a reader that panics on malformed data, which would otherwise crash the
+			// connection goroutine.
+			defer func() {
+				if r := recover(); r != nil {
+					mu.Lock()
+					defer mu.Unlock()
+					decodeErr = errors.Join(decodeErr, fmt.Errorf("decode packet %d: %v", header.PacketID, r))
+				}
+			}()
+			var pk packet.Packet
+			switch header.PacketID {
+			case packet.IDResourcePacksInfo:
+				pk = &packet.ResourcePacksInfo{}
+			case packet.IDResourcePackStack:
+				pk = &packet.ResourcePackStack{}
+			case packet.IDStartGame:
+				pk = &packet.StartGame{}
+			default:
+				return
+			}
+			pk.Marshal(protocol.NewReader(bytes.NewReader(payload), 0, false))
+			mu.Lock()
+			defer mu.Unlock()
+			packs = append(packs, pk)
+		},
+	}
+	conn, err := dialer.DialContext(ctx, "raknet", addr)
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, captureTimeout)
+	defer cancel()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-timeoutCtx.Done():
+			_ = conn.Close()
+		case <-done:
+		}
+	}()
+
+	if err := conn.DoSpawn(); err != nil {
+		return nil, fmt.Errorf("spawn on %s: %w", addr, err)
+	}
+	s := session.New(conn.GameData())
+	mu.Lock()
+	for _, pk := range packs {
+		s.Add(pk)
+	}
+	err = decodeErr
+	mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("capture %s: %w", addr, err)
+	}
+
+	pending := make(map[uint32]struct{}, len(handledPackets))
+	for _, id := range handledPackets {
+		if !slices.Contains(optionalPackets, id) {
+			pending[id] = struct{}{}
+		}
+	}
+	for {
+		pk, err := conn.ReadPacket()
+		if err != nil {
+			if len(pending) > 0 {
+				return nil, captureError(ctx, timeoutCtx, addr, pending, err)
+			}
+			break
+		}
+		id := pk.ID()
+		if slices.Contains(repeatedPackets, id) {
+			s.AddRepeated(pk)
+		} else if _, ok := s.Packets[id]; !ok && slices.Contains(handledPackets, id) {
+			delete(pending, id)
+			s.Add(pk)
+		} else {
+			continue
+		}
+		if len(pending) == 0 {
+			_ = conn.SetReadDeadline(time.Now().Add(captureQuietPeriod))
+		}
+	}
+	return s, nil
+}
+
+// captureError returns the error of a capture of the server at the address passed that ended with the error passed
+// before the pending packets were received, explaining why it ended.
+func captureError(ctx, timeoutCtx context.Context, addr string, pending map[uint32]struct{}, err error) error {
+	missing := strings.Join(packetNames(slices.Sorted(maps.Keys(pending))), ", ")
+	switch {
+	case ctx.Err() != nil:
+		return fmt.Errorf("capture %s: cancelled before receiving %s", addr, missing)
+	case errors.Is(timeoutCtx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("capture %s: did not receive %s within %v", addr, missing, captureTimeout)
+	}
+	return fmt.Errorf("capture %s: connection closed before receiving %s: %w", addr, missing, err)
+}
+
+// unseenPackets returns a warning for every optional and repeated packet that the session passed does not hold.
+// The server may not have sent these packets, but it may also have sent them after the capture ended.
+func unseenPackets(s *session.Session) []string {
+	var ids []uint32
+	for _, id := range optionalPackets {
+		if _, ok := s.Packets[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	for _, id := range repeatedPackets {
+		if len(s.Repeated[id]) == 0 {
+			ids = append(ids, id)
+		}
+	}
+	var warnings []string
+	for _, name := range packetNames(ids) {
+		warnings = append(warnings, fmt.Sprintf("server did not send a %s packet, its data is missing", name))
+	}
+	return warnings
+}
+
+// packetNames returns the type names of the packets sent by servers with the IDs passed, such as "CraftingData".
+func packetNames(ids []uint32) []string {
+	pool := packet.NewServerPool()
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		names = append(names, strings.TrimPrefix(fmt.Sprintf("%T", pool[id]()), "*packet."))
+	}
+	return names
+}
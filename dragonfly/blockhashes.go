@@ -0,0 +1,41 @@
+package dragonfly
+
+import (
+	"fmt"
+
+	"github.com/df-mc/datagen/data"
+)
+
+// BlockHashEntry represents a single block state in block_network_hashes.nbt, which maps every block state to the
+// network ID it has with block-network-ids-are-hashes enabled and to its sequential runtime ID otherwise.
+type BlockHashEntry struct {
+	Name        string         `json:"name" nbt:"name"`
+	States      map[string]any `json:"states" nbt:"states"`
+	NetworkHash int32          `json:"network_hash" nbt:"network_hash"`
+	RuntimeID   int32          `json:"runtime_id" nbt:"runtime_id"`
+}
+
+// newBlockHashes creates an entry for every block state in the Registry passed, in the order of their runtime IDs.
+// A warning is returned for every block state with the same network hash as a block state before it, as the hash
+// cannot tell those block states apart.
+func newBlockHashes(reg *data.Registry) ([]BlockHashEntry, []string) {
+	states := reg.States()
+	entries := make([]BlockHashEntry, 0, len(states))
+	seen := make(map[uint32]int, len(states))
+	var warnings []string
+	for rid, s := range states {
+		hash := data.NetworkHash(s.Name, s.Properties)
+		if other, ok := seen[hash]; ok {
+			warnings = append(warnings, fmt.Sprintf("network hash %d of block state %s %v collides with %s %v", int32(hash), s.Name, s.Properties, states[other].Name, states[other].Properties))
+		} else {
+			seen[hash] = rid
+		}
+		entries = append(entries, BlockHashEntry{
+			Name:        s.Name,
+			States:      s.Properties,
+			NetworkHash: int32(hash),
+			RuntimeID:   int32(rid),
+		})
+	}
+	return entries, warnings
+}
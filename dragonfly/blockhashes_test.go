@@ -0,0 +1,66 @@
+package dragonfly
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/df-mc/datagen/data"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+// testRegistry creates a Registry with the items passed and a palette holding a state without properties for
+// every block name passed, each placed by meta 0 of the item with its name.
+func testRegistry(t *testing.T, items []protocol.ItemEntry, blocks ...string) *data.Registry {
+	t.Helper()
+	var blockStates []byte
+	for _, name := range blocks {
+		b, err := nbt.Marshal(map[string]any{"name": name, "states": map[string]any{}, "version": int32(0)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		blockStates = append(blockStates, b...)
+	}
+	metaMap, err := json.Marshal(make([]int32, len(blocks)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	palette, err := data.ReadPalette(blockStates, metaMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data.NewRegistry(minecraft.GameData{Items: items}, palette)
+}
+
+func TestNewBlockHashes(t *testing.T) {
+	entries, warnings := newBlockHashes(testRegistry(t, nil, "minecraft:air", "minecraft:unknown"))
+	want := []BlockHashEntry{
+		{Name: "minecraft:air", States: map[string]any{}, NetworkHash: -604749536, RuntimeID: 0},
+		{Name: "minecraft:unknown", States: map[string]any{}, NetworkHash: -2, RuntimeID: 1},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, e := range entries {
+		if e.Name != want[i].Name || e.NetworkHash != want[i].NetworkHash || e.RuntimeID != want[i].RuntimeID {
+			t.Errorf("got entry %v, want %v", e, want[i])
+		}
+	}
+	if len(warnings) != 0 {
+		t.Errorf("got warnings %q, want none", warnings)
+	}
+}
+
+func TestNewBlockHashesCollision(t *testing.T) {
+	// These block names have the same FNV-1a hash.
+	entries, warnings := newBlockHashes(testRegistry(t, nil, "minecraft:block_132789", "minecraft:air", "minecraft:block_729192"))
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want every block state despite the collision", len(entries))
+	}
+	want := []string{"network hash 1135088847 of block state minecraft:block_729192 map[] collides with minecraft:block_132789 map[]"}
+	if !slices.Equal(warnings, want) {
+		t.Errorf("got warnings %q, want %q", warnings, want)
+	}
+}
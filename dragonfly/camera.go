@@ -0,0 +1,188 @@
+package dragonfly
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// CameraPreset represents a single vanilla camera preset in camera_presets.json and camera_presets.nbt. Fields
+// that the preset does not set are nil and inherited from the parent preset.
+type CameraPreset struct {
+	Name                    string                 `json:"name" nbt:"name"`
+	Parent                  string                 `json:"parent,omitempty" nbt:"parent,omitempty"`
+	PosX                    *float32               `json:"pos_x,omitempty" nbt:"pos_x,omitempty"`
+	PosY                    *float32               `json:"pos_y,omitempty" nbt:"pos_y,omitempty"`
+	PosZ                    *float32               `json:"pos_z,omitempty" nbt:"pos_z,omitempty"`
+	RotX                    *float32               `json:"rot_x,omitempty" nbt:"rot_x,omitempty"`
+	RotY                    *float32               `json:"rot_y,omitempty" nbt:"rot_y,omitempty"`
+	RotationSpeed           *float32               `json:"rotation_speed,omitempty" nbt:"rotation_speed,omitempty"`
+	SnapToTarget            *bool                  `json:"snap_to_target,omitempty" nbt:"snap_to_target,omitempty"`
+	HorizontalRotationLimit []float32              `json:"horizontal_rotation_limit,omitempty" nbt:"horizontal_rotation_limit,omitempty"`
+	VerticalRotationLimit   []float32              `json:"vertical_rotation_limit,omitempty" nbt:"vertical_rotation_limit,omitempty"`
+	ContinueTargeting       *bool                  `json:"continue_targeting,omitempty" nbt:"continue_targeting,omitempty"`
+	TrackingRadius          *float32               `json:"tracking_radius,omitempty" nbt:"tracking_radius,omitempty"`
+	ViewOffset              []float32              `json:"view_offset,omitempty" nbt:"view_offset,omitempty"`
+	EntityOffset            []float32              `json:"entity_offset,omitempty" nbt:"entity_offset,omitempty"`
+	Radius                  *float32               `json:"radius,omitempty" nbt:"radius,omitempty"`
+	MinYawLimit             *float32               `json:"min_yaw_limit,omitempty" nbt:"min_yaw_limit,omitempty"`
+	MaxYawLimit             *float32               `json:"max_yaw_limit,omitempty" nbt:"max_yaw_limit,omitempty"`
+	AudioListener           *string                `json:"audio_listener,omitempty" nbt:"audio_listener,omitempty"`
+	PlayerEffects           *bool                  `json:"player_effects,omitempty" nbt:"player_effects,omitempty"`
+	AimAssist               *CameraPresetAimAssist `json:"aim_assist,omitempty" nbt:"aim_assist,omitempty"`
+	ControlScheme           *string                `json:"control_scheme,omitempty" nbt:"control_scheme,omitempty"`
+}
+
+// CameraPresetAimAssist represents the aim assist settings of a CameraPreset.
+type CameraPresetAimAssist struct {
+	Preset     *string   `json:"preset,omitempty" nbt:"preset,omitempty"`
+	TargetMode *string   `json:"target_mode,omitempty" nbt:"target_mode,omitempty"`
+	Angle      []float32 `json:"angle,omitempty" nbt:"angle,omitempty"`
+	Distance   *float32  `json:"distance,omitempty" nbt:"distance,omitempty"`
+}
+
+// AimAssistPresets represents the structure of aim_assist_presets.json and aim_assist_presets.nbt, which hold the
+// vanilla aim assist categories and presets.
+type AimAssistPresets struct {
+	Categories []AimAssistCategory `json:"categories" nbt:"categories"`
+	Presets    []AimAssistPreset   `json:"presets" nbt:"presets"`
+}
+
+// AimAssistCategory represents a single aim assist category, which holds the priorities with which entities and
+// blocks are targeted.
+type AimAssistCategory struct {
+	Name                  string           `json:"name" nbt:"name"`
+	EntityPriorities      map[string]int32 `json:"entity_priorities" nbt:"entity_priorities"`
+	BlockPriorities       map[string]int32 `json:"block_priorities" nbt:"block_priorities"`
+	EntityDefaultPriority *int32           `json:"entity_default_priority,omitempty" nbt:"entity_default_priority,omitempty"`
+	BlockDefaultPriority  *int32           `json:"block_default_priority,omitempty" nbt:"block_default_priority,omitempty"`
+}
+
+// AimAssistPreset represents a single aim assist preset. ItemSettings maps item names to the name of the
+// AimAssistCategory used while holding them.
+type AimAssistPreset struct {
+	Identifier          string            `json:"identifier" nbt:"identifier"`
+	BlockExclusions     []string          `json:"block_exclusions" nbt:"block_exclusions"`
+	LiquidTargets       []string          `json:"liquid_targets" nbt:"liquid_targets"`
+	ItemSettings        map[string]string `json:"item_settings" nbt:"item_settings"`
+	DefaultItemSettings *string           `json:"default_item_settings,omitempty" nbt:"default_item_settings,omitempty"`
+	HandSettings        *string           `json:"hand_settings,omitempty" nbt:"hand_settings,omitempty"`
+}
+
+var (
+	// cameraAudioListeners holds the names of the audio listeners of camera presets, indexed by their value.
+	cameraAudioListeners = []string{"camera", "player"}
+	// cameraControlSchemes holds the names of the control schemes of camera presets, indexed by their value.
+	cameraControlSchemes = []string{
+		"locked_player_relative_strafe",
+		"camera_relative",
+		"camera_relative_strafe",
+		"player_relative",
+		"player_relative_strafe",
+	}
+	// aimAssistTargetModes holds the names of the aim assist target modes, indexed by their value.
+	aimAssistTargetModes = []string{"angle", "distance"}
+)
+
+// newCameraPresets creates the camera presets held by the CameraPresets packet passed, in the order sent.
+func newCameraPresets(pk *packet.CameraPresets) []CameraPreset {
+	presets := make([]CameraPreset, 0, len(pk.Presets))
+	for _, p := range pk.Presets {
+		preset := CameraPreset{
+			Name:                    p.Name,
+			Parent:                  p.Parent,
+			PosX:                    optional(p.PosX),
+			PosY:                    optional(p.PosY),
+			PosZ:                    optional(p.PosZ),
+			RotX:                    optional(p.RotX),
+			RotY:                    optional(p.RotY),
+			RotationSpeed:           optional(p.RotationSpeed),
+			SnapToTarget:            optional(p.SnapToTarget),
+			HorizontalRotationLimit: optionalVec2(p.HorizontalRotationLimit),
+			VerticalRotationLimit:   optionalVec2(p.VerticalRotationLimit),
+			ContinueTargeting:       optional(p.ContinueTargeting),
+			TrackingRadius:          optional(p.TrackingRadius),
+			ViewOffset:              optionalVec2(p.ViewOffset),
+			Radius:                  optional(p.Radius),
+			MinYawLimit:             optional(p.MinYawLimit),
+			MaxYawLimit:             optional(p.MaxYawLimit),
+			PlayerEffects:           optional(p.PlayerEffects),
+		}
+		if v, ok := p.EntityOffset.Value(); ok {
+			preset.EntityOffset = v[:]
+		}
+		if v, ok := p.AudioListener.Value(); ok {
+			name := lookup(cameraAudioListeners, v, "audio listener")
+			preset.AudioListener = &name
+		}
+		if v, ok := p.ControlScheme.Value(); ok {
+			name := lookup(cameraControlSchemes, v, "control scheme")
+			preset.ControlScheme = &name
+		}
+		if v, ok := p.AimAssist.Value(); ok {
+			preset.AimAssist = &CameraPresetAimAssist{
+				Preset:   optional(v.Preset),
+				Angle:    optionalVec2(v.Angle),
+				Distance: optional(v.Distance),
+			}
+			if mode, ok := v.TargetMode.Value(); ok {
+				name := lookup(aimAssistTargetModes, uint32(mode), "aim assist target mode")
+				preset.AimAssist.TargetMode = &name
+			}
+		}
+		presets = append(presets, preset)
+	}
+	return presets
+}
+
+// newAimAssistPresets creates the AimAssistPresets from the CameraAimAssistPresets packet passed.
+func newAimAssistPresets(pk *packet.CameraAimAssistPresets) AimAssistPresets {
+	presets := AimAssistPresets{Categories: []AimAssistCategory{}, Presets: []AimAssistPreset{}}
+	for _, c := range pk.Categories {
+		category := AimAssistCategory{
+			Name:                  c.Name,
+			EntityPriorities:      make(map[string]int32, len(c.Priorities.Entities)),
+			BlockPriorities:       make(map[string]int32, len(c.Priorities.Blocks)),
+			EntityDefaultPriority: optional(c.Priorities.EntityDefault),
+			BlockDefaultPriority:  optional(c.Priorities.BlockDefault),
+		}
+		for _, p := range c.Priorities.Entities {
+			category.EntityPriorities[p.Identifier] = p.Priority
+		}
+		for _, p := range c.Priorities.Blocks {
+			category.BlockPriorities[p.Identifier] = p.Priority
+		}
+		presets.Categories = append(presets.Categories, category)
+	}
+	for _, p := range pk.Presets {
+		preset := AimAssistPreset{
+			Identifier:          p.Identifier,
+			BlockExclusions:     append([]string{}, p.BlockExclusions...),
+			LiquidTargets:       append([]string{}, p.LiquidTargets...),
+			ItemSettings:        make(map[string]string, len(p.ItemSettings)),
+			DefaultItemSettings: optional(p.DefaultItemSettings),
+			HandSettings:        optional(p.HandSettings),
+		}
+		for _, s := range p.ItemSettings {
+			preset.ItemSettings[s.Item] = s.Category
+		}
+		presets.Presets = append(presets.Presets, preset)
+	}
+	return presets
+}
+
+// optional returns a pointer to the value of the protocol.Optional passed, or nil if it has no value.
+func optional[T any](o protocol.Optional[T]) *T {
+	if v, ok := o.Value(); ok {
+		return &v
+	}
+	return nil
+}
+
+// optionalVec2 returns the value of the protocol.Optional passed as a slice, or nil if it has no value.
+func optionalVec2(o protocol.Optional[mgl32.Vec2]) []float32 {
+	if v, ok := o.Value(); ok {
+		return v[:]
+	}
+	return nil
+}
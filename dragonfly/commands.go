@@ -0,0 +1,176 @@
+package dragonfly
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// Command represents the structure of a command in commands.json, with all indices of the AvailableCommands
+// packet resolved.
+type Command struct {
+	Name               string            `json:"name"`
+	Description        string            `json:"description"`
+	Aliases            []string          `json:"aliases,omitempty"`
+	PermissionLevel    string            `json:"permission_level"`
+	Flags              uint16            `json:"flags,omitempty"`
+	ChainedSubcommands []string          `json:"chained_subcommands,omitempty"`
+	Overloads          []CommandOverload `json:"overloads"`
+}
+
+// CommandOverload represents a single overload of a Command.
+type CommandOverload struct {
+	Chaining   bool               `json:"chaining,omitempty"`
+	Parameters []CommandParameter `json:"parameters"`
+}
+
+// CommandParameter represents a single parameter of a CommandOverload. Type is either the name of a basic type,
+// such as "int" or "target", or "enum" or "soft_enum", in which case Enum holds the enum.
+type CommandParameter struct {
+	Name     string       `json:"name"`
+	Type     string       `json:"type"`
+	Optional bool         `json:"optional,omitempty"`
+	Suffix   string       `json:"suffix,omitempty"`
+	Enum     *CommandEnum `json:"enum,omitempty"`
+	Options  []string     `json:"options,omitempty"`
+}
+
+// CommandEnum represents an enum used by a CommandParameter. Constraints maps values of the enum to the
+// constraints that apply to them, such as "cheats_enabled".
+type CommandEnum struct {
+	Name        string              `json:"name"`
+	Values      []string            `json:"values"`
+	Constraints map[string][]string `json:"constraints,omitempty"`
+}
+
+var (
+	// commandPermissionLevels holds the names of the permission levels of commands, indexed by their value.
+	commandPermissionLevels = []string{"any", "game_directors", "admin", "host", "owner", "internal"}
+	// commandArgTypes maps the basic command argument types to their names.
+	commandArgTypes = map[uint32]string{
+		protocol.CommandArgTypeInt:             "int",
+		protocol.CommandArgTypeFloat:           "float",
+		protocol.CommandArgTypeValue:           "value",
+		protocol.CommandArgTypeWildcardInt:     "wildcard_int",
+		protocol.CommandArgTypeOperator:        "operator",
+		protocol.CommandArgTypeCompareOperator: "compare_operator",
+		protocol.CommandArgTypeTarget:          "target",
+		protocol.CommandArgTypeWildcardTarget:  "wildcard_target",
+		protocol.CommandArgTypeFilepath:        "filepath",
+		protocol.CommandArgTypeIntegerRange:    "integer_range",
+		protocol.CommandArgTypeEquipmentSlots:  "equipment_slots",
+		protocol.CommandArgTypeString:          "string",
+		protocol.CommandArgTypeBlockPosition:   "block_position",
+		protocol.CommandArgTypePosition:        "position",
+		protocol.CommandArgTypeMessage:         "message",
+		protocol.CommandArgTypeRawText:         "raw_text",
+		protocol.CommandArgTypeJSON:            "json",
+		protocol.CommandArgTypeBlockStates:     "block_states",
+		protocol.CommandArgTypeCommand:         "command",
+	}
+	// commandParamOptions holds the names of the command parameter options, indexed by their bit.
+	commandParamOptions = []string{"collapse_enum", "has_semantic_constraint", "as_chained_command"}
+	// commandConstraints holds the names of command enum constraints, indexed by their value.
+	commandConstraints = []string{"cheats_enabled", "operator_permissions", "host_permissions"}
+)
+
+// newCommands resolves all commands in the AvailableCommands packet passed, sorted by their name.
+func newCommands(pk *packet.AvailableCommands) []Command {
+	enums := make([]CommandEnum, len(pk.Enums))
+	for i, enum := range pk.Enums {
+		enums[i] = CommandEnum{Name: enum.Type, Values: make([]string, 0, len(enum.ValueIndices))}
+		for _, index := range enum.ValueIndices {
+			enums[i].Values = append(enums[i].Values, lookup(pk.EnumValues, index, "enum value"))
+		}
+	}
+	for _, c := range pk.Constraints {
+		enum := &enums[lookupIndex(len(enums), c.EnumIndex, "enum")]
+		value := lookup(pk.EnumValues, c.EnumValueIndex, "enum value")
+		if enum.Constraints == nil {
+			enum.Constraints = make(map[string][]string)
+		}
+		for _, constraint := range c.Constraints {
+			enum.Constraints[value] = append(enum.Constraints[value], lookup(commandConstraints, constraint, "constraint"))
+		}
+	}
+	softEnums := make([]CommandEnum, len(pk.DynamicEnums))
+	for i, enum := range pk.DynamicEnums {
+		softEnums[i] = CommandEnum{Name: enum.Type, Values: slices.Clone(enum.Values)}
+	}
+
+	commands := make([]Command, 0, len(pk.Commands))
+	for _, c := range pk.Commands {
+		command := Command{
+			Name:            c.Name,
+			Description:     c.Description,
+			PermissionLevel: lookup(commandPermissionLevels, c.PermissionLevel, "permission level"),
+			Flags:           c.Flags,
+			Overloads:       make([]CommandOverload, 0, len(c.Overloads)),
+		}
+		// An aliases offset with all bits set means the command has no aliases.
+		if c.AliasesOffset != ^uint32(0) {
+			command.Aliases = enums[lookupIndex(len(enums), c.AliasesOffset, "alias enum")].Values
+		}
+		for _, offset := range c.ChainedSubcommandOffsets {
+			command.ChainedSubcommands = append(command.ChainedSubcommands, lookup(pk.ChainedSubcommands, offset, "chained subcommand").Name)
+		}
+		for _, o := range c.Overloads {
+			overload := CommandOverload{Chaining: o.Chaining, Parameters: make([]CommandParameter, 0, len(o.Parameters))}
+			for _, p := range o.Parameters {
+				overload.Parameters = append(overload.Parameters, newCommandParameter(p, enums, softEnums, pk.Suffixes))
+			}
+			command.Overloads = append(command.Overloads, overload)
+		}
+		commands = append(commands, command)
+	}
+	slices.SortFunc(commands, func(a, b Command) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return commands
+}
+
+// newCommandParameter resolves the type of the command parameter passed using the enums, soft enums and suffixes
+// of the AvailableCommands packet.
+func newCommandParameter(p protocol.CommandParameter, enums, softEnums []CommandEnum, suffixes []string) CommandParameter {
+	param := CommandParameter{Name: p.Name, Optional: p.Optional}
+	index := p.Type & 0xffff
+	switch {
+	case p.Type&protocol.CommandArgSoftEnum != 0:
+		enum := softEnums[lookupIndex(len(softEnums), index, "soft enum")]
+		param.Type, param.Enum = "soft_enum", &enum
+	case p.Type&protocol.CommandArgEnum != 0:
+		enum := enums[lookupIndex(len(enums), index, "enum")]
+		param.Type, param.Enum = "enum", &enum
+	case p.Type&protocol.CommandArgSuffixed != 0:
+		param.Type, param.Suffix = "int", lookup(suffixes, index, "suffix")
+	default:
+		name, ok := commandArgTypes[index]
+		if !ok {
+			name = fmt.Sprintf("unknown_%d", index)
+		}
+		param.Type = name
+	}
+	for bit, name := range commandParamOptions {
+		if p.Options&(1<<bit) != 0 {
+			param.Options = append(param.Options, name)
+		}
+	}
+	return param
+}
+
+// lookup returns the value at the index passed in s, panicking with a descriptive error if the index is out of
+// range.
+func lookup[T any, I uint8 | uint16 | uint32 | uint](s []T, index I, kind string) T {
+	return s[lookupIndex(len(s), index, kind)]
+}
+
+// lookupIndex converts the index passed to an int, panicking if it is not lower than n.
+func lookupIndex[I uint8 | uint16 | uint32 | uint](n int, index I, kind string) int {
+	if uint64(index) >= uint64(n) {
+		panic(fmt.Errorf("%s index %d out of range (%d)", kind, index, n))
+	}
+	return int(index)
+}
@@ -0,0 +1,76 @@
+package dragonfly
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// Dimensions represents the structure of dimensions.json and dimensions.nbt. Spawn is the name of the dimension
+// that players spawn in, or its numeric ID if it is not a vanilla dimension. Definitions holds the definitions of
+// the vanilla dimensions, replaced by or complemented with the definitions sent by the server, sorted by name.
+type Dimensions struct {
+	Spawn       string      `json:"spawn" nbt:"spawn"`
+	Definitions []Dimension `json:"definitions" nbt:"definitions"`
+}
+
+// Dimension represents a single dimension definition with its height range and generator type. The server only
+// sends the DimensionData packet for dimensions that differ from vanilla, so Default is true for definitions that
+// were not received from the server and hold the known vanilla values instead.
+type Dimension struct {
+	Name      string `json:"name" nbt:"name"`
+	MinHeight int32  `json:"min_height" nbt:"min_height"`
+	MaxHeight int32  `json:"max_height" nbt:"max_height"`
+	Generator string `json:"generator" nbt:"generator"`
+	Default   bool   `json:"default" nbt:"default"`
+}
+
+var (
+	// dimensionNames holds the names of the vanilla dimensions, indexed by their ID.
+	dimensionNames = []string{"minecraft:overworld", "minecraft:nether", "minecraft:the_end"}
+	// dimensionGenerators holds the names of the generator types of dimensions, indexed by their value.
+	dimensionGenerators = []string{"legacy", "overworld", "flat", "nether", "end", "void"}
+)
+
+// vanillaDimensions returns the definitions of the vanilla dimensions, sorted by name, which are used for
+// dimensions that the server does not send a definition for.
+func vanillaDimensions() []Dimension {
+	return []Dimension{
+		{Name: "minecraft:nether", MinHeight: 0, MaxHeight: 128, Generator: "nether", Default: true},
+		{Name: "minecraft:overworld", MinHeight: -64, MaxHeight: 320, Generator: "overworld", Default: true},
+		{Name: "minecraft:the_end", MinHeight: 0, MaxHeight: 256, Generator: "end", Default: true},
+	}
+}
+
+// dimensionName returns the name of the vanilla dimension with the ID passed, or the ID itself if no vanilla
+// dimension has that ID.
+func dimensionName(id int32) string {
+	if id < 0 || int(id) >= len(dimensionNames) {
+		return strconv.Itoa(int(id))
+	}
+	return dimensionNames[id]
+}
+
+// newDimensions replaces the dimension definitions passed with those held by the DimensionData packet passed with
+// the same name, adding the definitions of other dimensions, and sorts them by name.
+func newDimensions(dimensions []Dimension, pk *packet.DimensionData) []Dimension {
+	for _, d := range pk.Definitions {
+		dimension := Dimension{
+			Name:      d.Name,
+			MinHeight: d.Range[0],
+			MaxHeight: d.Range[1],
+			Generator: lookup(dimensionGenerators, uint32(d.Generator), "dimension generator"),
+		}
+		if i := slices.IndexFunc(dimensions, func(e Dimension) bool { return e.Name == d.Name }); i != -1 {
+			dimensions[i] = dimension
+			continue
+		}
+		dimensions = append(dimensions, dimension)
+	}
+	slices.SortFunc(dimensions, func(a, b Dimension) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return dimensions
+}
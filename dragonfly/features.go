@@ -0,0 +1,103 @@
+package dragonfly
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/df-mc/datagen/write"
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// FeatureIndexEntry represents a single world generation feature in features/index.json. File is the path of the
+// file holding the definition of the feature, relative to the features directory.
+type FeatureIndexEntry struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+}
+
+// newFeatures returns the JSON definitions of all world generation features in the FeatureRegistry packet
+// passed, indexed by the name of the feature. Features that would be written to the same file as a feature before
+// them, such as "foo" after "minecraft:foo", are left out and a warning is returned for them.
+func newFeatures(pk *packet.FeatureRegistry) (map[string]json.RawMessage, []string) {
+	features := make(map[string]json.RawMessage, len(pk.Features))
+	files := make(map[string]string, len(pk.Features))
+	var warnings []string
+	for _, feature := range pk.Features {
+		if !json.Valid(feature.JSON) {
+			panic(fmt.Errorf("feature %s has invalid JSON definition", feature.Name))
+		}
+		if warning, ok := addFeature(features, files, feature.Name, feature.JSON); !ok {
+			warnings = append(warnings, warning)
+		}
+	}
+	return features, warnings
+}
+
+// featureFiles returns the names of the features passed indexed by the file that they are written to.
+func featureFiles(features map[string]json.RawMessage) map[string]string {
+	files := make(map[string]string, len(features))
+	for name := range features {
+		files[featureFile(name)] = name
+	}
+	return files
+}
+
+// addFeature adds the feature with the name and definition passed to the features passed, unless it is already
+// present or another feature is written to the same file according to the files passed, which are updated. False
+// is returned along with a warning if the feature was left out because of another feature with the same file.
+func addFeature(features map[string]json.RawMessage, files map[string]string, name string, definition json.RawMessage) (string, bool) {
+	if _, ok := features[name]; ok {
+		return "", true
+	}
+	file := featureFile(name)
+	if other, ok := files[file]; ok {
+		return fmt.Sprintf("features %s and %s are both written to %s, left out %s", other, name, file, name), false
+	}
+	features[name], files[file] = definition, name
+	return "", true
+}
+
+// newJigsawStructures decodes the network NBT of jigsaw structure rules in the JigsawStructureData packet passed.
+func newJigsawStructures(pk *packet.JigsawStructureData) map[string]any {
+	var structures map[string]any
+	if err := nbt.UnmarshalEncoding(pk.StructureData, &structures, nbt.NetworkLittleEndian); err != nil {
+		panic(fmt.Errorf("failed to unmarshal jigsaw structure data: %w", err))
+	}
+	return structures
+}
+
+// writeFeatures writes the definition of every feature passed to its own file in the directory passed, along with
+// an index.json file listing all features sorted by their name. It panics if two features would be written to the
+// same file, which addFeature prevents.
+func writeFeatures(fsys write.FS, dir string, features map[string]json.RawMessage) {
+	index := make([]FeatureIndexEntry, 0, len(features))
+	files := make(map[string]string, len(features))
+	for _, name := range slices.Sorted(maps.Keys(features)) {
+		file := featureFile(name)
+		if other, ok := files[file]; ok {
+			panic(fmt.Errorf("features %s and %s are both written to %s", other, name, file))
+		}
+		files[file] = name
+		write.JSON(fsys, path.Join(dir, file), features[name])
+		index = append(index, FeatureIndexEntry{Name: name, File: file})
+	}
+	write.JSON(fsys, path.Join(dir, "index.json"), index)
+}
+
+// featureFile returns the path of the file that the definition of the feature with the name passed is written to.
+// The namespace of the feature, such as "minecraft", is used as directory.
+func featureFile(name string) string {
+	namespace, id, ok := strings.Cut(name, ":")
+	if !ok {
+		namespace, id = "minecraft", name
+	}
+	if id == "" || strings.ContainsAny(namespace+id, `/\`) || strings.HasPrefix(namespace, ".") {
+		panic(fmt.Errorf("feature name %q cannot be used as file name", name))
+	}
+	return path.Join(namespace, id+".json")
+}
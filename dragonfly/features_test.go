@@ -0,0 +1,93 @@
+package dragonfly
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+func TestFeatureFile(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"minecraft:oak_tree_feature", "minecraft/oak_tree_feature.json"},
+		{"oak_tree_feature", "minecraft/oak_tree_feature.json"},
+		{"custom:tree", "custom/tree.json"},
+	}
+	for _, tt := range tests {
+		if got := featureFile(tt.name); got != tt.want {
+			t.Errorf("featureFile(%s) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+	for _, name := range []string{"minecraft:", "../tree", "minecraft:a/b", `a\b`} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("featureFile(%q) did not panic", name)
+				}
+			}()
+			featureFile(name)
+		}()
+	}
+}
+
+func TestNewFeaturesDuplicateFile(t *testing.T) {
+	features, warnings := newFeatures(&packet.FeatureRegistry{Features: []protocol.GenerationFeature{
+		{Name: "minecraft:tree", JSON: []byte(`{"a":1}`)},
+		{Name: "tree", JSON: []byte(`{"b":2}`)},
+		{Name: "custom:tree", JSON: []byte(`{"c":3}`)},
+	}})
+	want := map[string]json.RawMessage{"minecraft:tree": []byte(`{"a":1}`), "custom:tree": []byte(`{"c":3}`)}
+	if !reflect.DeepEqual(features, want) {
+		t.Errorf("got features %s, want %s", features, want)
+	}
+	wantWarnings := []string{"features minecraft:tree and tree are both written to minecraft/tree.json, left out tree"}
+	if !slices.Equal(warnings, wantWarnings) {
+		t.Errorf("got warnings %q, want %q", warnings, wantWarnings)
+	}
+}
+
+func TestMergeFeaturesDuplicateFile(t *testing.T) {
+	o := NewOutput(nil)
+	o.Features = map[string]json.RawMessage{"tree": []byte(`{}`)}
+	other := NewOutput(nil)
+	other.Features = map[string]json.RawMessage{"minecraft:tree": []byte(`{}`), "minecraft:bush": []byte(`{}`)}
+	o.Merge(other, "experiment")
+	if _, ok := o.Features["minecraft:tree"]; ok || len(o.Features) != 2 {
+		t.Errorf("got features %s, want tree and minecraft:bush", o.Features)
+	}
+	want := []string{"features tree and minecraft:tree are both written to minecraft/tree.json, left out minecraft:tree"}
+	if !slices.Equal(o.Warnings, want) {
+		t.Errorf("got warnings %q, want %q", o.Warnings, want)
+	}
+}
+
+func TestWriteFeatures(t *testing.T) {
+	fsys := captureFS{}
+	writeFeatures(fsys, "features", map[string]json.RawMessage{"minecraft:tree": []byte(`{}`), "custom:bush": []byte(`{}`)})
+	var index []FeatureIndexEntry
+	if err := json.Unmarshal(fsys["features/index.json"], &index); err != nil {
+		t.Fatal(err)
+	}
+	want := []FeatureIndexEntry{{Name: "custom:bush", File: "custom/bush.json"}, {Name: "minecraft:tree", File: "minecraft/tree.json"}}
+	if !reflect.DeepEqual(index, want) {
+		t.Errorf("got index %+v, want %+v", index, want)
+	}
+	for _, e := range want {
+		if _, ok := fsys["features/"+e.File]; !ok {
+			t.Errorf("%s not written: %v", e.File, fsys)
+		}
+	}
+
+	defer func() {
+		if r := recover(); fmt.Sprint(r) != "features minecraft:tree and tree are both written to minecraft/tree.json" {
+			t.Errorf("got panic %v for features written to the same file", r)
+		}
+	}()
+	writeFeatures(captureFS{}, "features", map[string]json.RawMessage{"minecraft:tree": []byte(`{}`), "tree": []byte(`{}`)})
+}
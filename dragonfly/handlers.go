@@ -1,98 +1,294 @@
 package dragonfly
 
 import (
+	"cmp"
+	"encoding/json"
 	"fmt"
 	"math"
+	"slices"
+	"strings"
 
 	"github.com/df-mc/datagen/data"
 	"github.com/df-mc/datagen/write"
-	"github.com/df-mc/dragonfly/server/world/chunk"
 	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
 	"github.com/sandertv/gophertunnel/minecraft/protocol"
 	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
 )
 
-func HandleGameData(gameData minecraft.GameData) {
-	vanillaItems := make(map[string]VanillaItemEntry)
+// Output holds all data generated for dragonfly. It is filled by the Handle methods and written to a write.FS
+// using Write. Outputs generated from different sessions may be combined using Merge.
+type Output struct {
+	GameSettings GameSettings
+	Dimensions   Dimensions
+	VanillaItems map[string]VanillaItemEntry
+	CustomItems  map[string]VanillaItemEntry
+	CustomBlocks map[string]CustomBlock
+	BlockHashes  []BlockHashEntry
+	MetaStates   map[string]map[string]map[string]any
+	MetaCoverage MetaCoverage
+	Entities     []string
+	Properties   map[string][]EntityProperty
+	Biomes       map[string]uint16
+	Commands     []Command
+	Cameras      []CameraPreset
+	AimAssist    AimAssistPresets
+	Features     map[string]json.RawMessage
+	Jigsaw       map[string]any
+	Furnace      []FurnaceRecipe
+	Crafting     CraftingRecipes
+	Chemistry    CraftingRecipes
+	Smithing     []ShapelessRecipe
+	SmithingTrim []ShapelessRecipe
+	Potions      PotionRecipes
+	RecipeBook   []RecipeBookEntry
+	Trim         TrimData
+	Creative     CreativeContent
+
+	// Warnings holds the problems found while handling the data that did not prevent generating it.
+	Warnings []string
+
+	reg *data.Registry
+}
+
+// NewOutput returns a new, empty Output that resolves items and block states using the Registry passed.
+func NewOutput(reg *data.Registry) *Output {
+	return &Output{
+		reg:          reg,
+		Dimensions:   Dimensions{Definitions: vanillaDimensions()},
+		VanillaItems: make(map[string]VanillaItemEntry),
+		CustomItems:  make(map[string]VanillaItemEntry),
+		CustomBlocks: make(map[string]CustomBlock),
+		Biomes:       make(map[string]uint16),
+		Properties:   make(map[string][]EntityProperty),
+		Features:     make(map[string]json.RawMessage),
+		Jigsaw:       make(map[string]any),
+	}
+}
+
+// Write writes all data held by the Output to the FS passed.
+func (o *Output) Write(fsys write.FS) {
+	write.JSON(fsys, "game_settings.json", o.GameSettings)
+	write.NBT(fsys, "game_settings.nbt", o.GameSettings)
+	write.JSON(fsys, "dimensions.json", o.Dimensions)
+	write.NBT(fsys, "dimensions.nbt", o.Dimensions)
+
+	write.NBT(fsys, "server/world/vanilla_items.nbt", o.VanillaItems)
+	write.Go(fsys, "vanilla/items.go", itemsTemplate, newItemSource(o.VanillaItems))
+	write.JSON(fsys, "custom_items.json", o.CustomItems)
+	write.NBT(fsys, "custom_items.nbt", o.CustomItems)
+	write.JSON(fsys, "custom_blocks.json", o.CustomBlocks)
+	write.NBT(fsys, "custom_blocks.nbt", o.CustomBlocks)
+	write.JSON(fsys, "block_network_hashes.json", o.BlockHashes)
+	write.NBT(fsys, "block_network_hashes.nbt", o.BlockHashes)
+	write.JSON(fsys, "item_meta_block_states.json", o.MetaStates)
+	write.NBT(fsys, "item_meta_block_states.nbt", o.MetaStates)
+	write.JSON(fsys, "meta_coverage.json", o.MetaCoverage)
+
+	src := entitySource{Package: sourcePackage, Entities: o.Entities}
+	checkIdentifiers(src.Entities, func(id string) string { return id })
+	write.Go(fsys, "vanilla/entities.go", entitiesTemplate, src)
+	write.JSON(fsys, "entity_properties.json", o.Properties)
+	write.NBT(fsys, "entity_properties.nbt", o.Properties)
+	write.Go(fsys, "vanilla/biomes.go", biomesTemplate, newBiomeSource(o.Biomes))
+
+	write.JSON(fsys, "commands.json", o.Commands)
+	write.Go(fsys, "vanilla/commands.go", commandsTemplate, commandSource{Package: sourcePackage, Commands: o.Commands})
+
+	write.JSON(fsys, "camera_presets.json", o.Cameras)
+	write.NBT(fsys, "camera_presets.nbt", o.Cameras)
+	write.JSON(fsys, "aim_assist_presets.json", o.AimAssist)
+	write.NBT(fsys, "aim_assist_presets.nbt", o.AimAssist)
+
+	writeFeatures(fsys, "features", o.Features)
+	write.JSON(fsys, "jigsaw_structures.json", o.Jigsaw)
+	write.NBT(fsys, "jigsaw_structures.nbt", o.Jigsaw)
+
+	write.NBT(fsys, "server/item/recipe/furnace_data.nbt", o.Furnace)
+	write.NBT(fsys, "server/item/recipe/crafting_data.nbt", o.Crafting)
+	write.NBT(fsys, "server/item/recipe/chemistry_data.nbt", o.Chemistry)
+	write.NBT(fsys, "server/item/recipe/smithing_data.nbt", o.Smithing)
+	write.NBT(fsys, "server/item/recipe/smithing_trim_data.nbt", o.SmithingTrim)
+	write.NBT(fsys, "server/item/recipe/potion_data.nbt", o.Potions)
+	write.NBT(fsys, "server/item/recipe/recipe_book.nbt", o.RecipeBook)
+	write.NBT(fsys, "server/item/trim_data.nbt", o.Trim)
+
+	write.NBT(fsys, "server/item/creative/creative_items.nbt", o.Creative)
+	write.Go(fsys, "vanilla/creative.go", creativeTemplate, creativeSource{Package: sourcePackage, Groups: o.Creative.Groups})
+}
+
+func (o *Output) HandleGameData(gameData minecraft.GameData) {
+	o.GameSettings = newGameSettings(gameData)
+	var collisions []string
+	o.BlockHashes, collisions = newBlockHashes(o.reg)
+	o.Warnings = append(o.Warnings, collisions...)
+	o.MetaStates = newItemMetaStates(o.reg)
+	o.MetaCoverage = newMetaCoverage(o.reg, gameData)
+	if n := len(o.MetaCoverage.ItemsWithoutMeta); n > 0 {
+		o.warnf("%d block items have no meta mapping in the palette, which may be outdated, see meta_coverage.json", n)
+	}
+	if n := len(o.MetaCoverage.BlocksWithoutItem); n > 0 {
+		o.warnf("%d blocks are not placed by any block item, see meta_coverage.json", n)
+	}
+	o.Dimensions.Spawn = dimensionName(gameData.Dimension)
+	for _, block := range gameData.CustomBlocks {
+		o.CustomBlocks[block.Name] = CustomBlock{Properties: block.Properties, States: data.CustomBlockStates(block)}
+	}
 	for _, item := range gameData.Items {
-		data.ItemNameToNetworkID[item.Name] = int32(item.RuntimeID)
-		data.ItemNetworkIDToName[int32(item.RuntimeID)] = item.Name
-		vanillaItems[item.Name] = VanillaItemEntry{
+		entry := VanillaItemEntry{
 			RuntimeID:      int32(item.RuntimeID),
 			ComponentBased: item.ComponentBased,
 			Version:        item.Version,
 			Data:           item.Data,
+			Tags:           Tags{Education: o.reg.IsEducationItem(item.Name)},
 		}
+		// Items outside the minecraft namespace are added by behaviour packs and are exported separately.
+		if !strings.HasPrefix(item.Name, "minecraft:") {
+			o.CustomItems[item.Name] = entry
+			continue
+		}
+		o.VanillaItems[item.Name] = entry
 	}
-	write.NBT("output/dragonfly/server/world/vanilla_items.nbt", vanillaItems)
 }
 
-func HandleCraftingData(pk *packet.CraftingData) {
-	var (
-		furnace []FurnaceRecipe
-		shaped  []ShapedRecipe
-		potions []PotionRecipe
+func (o *Output) HandleDimensionData(pk *packet.DimensionData) {
+	o.Dimensions.Definitions = newDimensions(o.Dimensions.Definitions, pk)
+}
+
+func (o *Output) HandleAvailableActorIdentifiers(pk *packet.AvailableActorIdentifiers) {
+	var identifiers ActorIdentifiers
+	err := nbt.Unmarshal(pk.SerialisedEntityIdentifiers, &identifiers)
+	if err != nil {
+		panic(fmt.Errorf("failed to unmarshal entity identifiers: %w", err))
+	}
+	list := identifiers.IDList
+	slices.SortStableFunc(list, func(a, b ActorIdentifier) int {
+		return cmp.Compare(a.RuntimeID, b.RuntimeID)
+	})
+	o.Entities = o.Entities[:0]
+	for _, id := range list {
+		o.Entities = append(o.Entities, id.ID)
+	}
+}
 
-		shapeless, smithing, smithingTrim []ShapelessRecipe
-		potionContainerChanges            []PotionContainerChangeRecipe
-	)
+func (o *Output) HandleSyncActorProperty(pk *packet.SyncActorProperty) {
+	entity, properties := newEntityProperties(pk)
+	o.Properties[entity] = properties
+}
+
+func (o *Output) HandleBiomeDefinitionList(pk *packet.BiomeDefinitionList) {
+	for _, definition := range pk.BiomeDefinitions {
+		if id, ok := definition.BiomeID.Value(); ok {
+			o.Biomes[pk.StringList[definition.NameIndex]] = id
+		}
+	}
+}
+
+func (o *Output) HandleAvailableCommands(pk *packet.AvailableCommands) {
+	o.Commands = newCommands(pk)
+}
+
+func (o *Output) HandleCameraPresets(pk *packet.CameraPresets) {
+	o.Cameras = newCameraPresets(pk)
+}
+
+func (o *Output) HandleCameraAimAssistPresets(pk *packet.CameraAimAssistPresets) {
+	o.AimAssist = newAimAssistPresets(pk)
+}
+
+func (o *Output) HandleFeatureRegistry(pk *packet.FeatureRegistry) {
+	var warnings []string
+	o.Features, warnings = newFeatures(pk)
+	o.Warnings = append(o.Warnings, warnings...)
+}
+
+func (o *Output) HandleJigsawStructureData(pk *packet.JigsawStructureData) {
+	o.Jigsaw = newJigsawStructures(pk)
+}
+
+func (o *Output) HandleCraftingData(pk *packet.CraftingData) {
+	o.Furnace, o.Crafting, o.Chemistry, o.Smithing, o.SmithingTrim, o.Potions = nil, CraftingRecipes{}, CraftingRecipes{}, nil, nil, PotionRecipes{}
 	for _, recipe := range pk.Recipes {
 		switch recipe := recipe.(type) {
 		case *protocol.FurnaceRecipe:
-			furnace = append(furnace, NewFurnaceRecipe(*recipe))
+			o.Furnace = append(o.Furnace, NewFurnaceRecipe(o.reg, *recipe))
 		case *protocol.FurnaceDataRecipe:
-			furnace = append(furnace, NewFurnaceRecipe(recipe.FurnaceRecipe))
+			o.Furnace = append(o.Furnace, NewFurnaceRecipe(o.reg, recipe.FurnaceRecipe))
 		case *protocol.ShapelessRecipe:
-			shapeless = append(shapeless, NewShapelessRecipe(*recipe))
+			o.Crafting.Shapeless = append(o.Crafting.Shapeless, NewShapelessRecipe(o.reg, *recipe))
 		case *protocol.ShapedRecipe:
-			shaped = append(shaped, NewShapedRecipe(*recipe))
+			o.Crafting.Shaped = append(o.Crafting.Shaped, NewShapedRecipe(o.reg, *recipe))
+		case *protocol.ShapelessChemistryRecipe:
+			r := NewShapelessRecipe(o.reg, recipe.ShapelessRecipe)
+			r.Education = true
+			o.Chemistry.Shapeless = append(o.Chemistry.Shapeless, r)
+		case *protocol.ShapedChemistryRecipe:
+			r := NewShapedRecipe(o.reg, recipe.ShapedRecipe)
+			r.Education = true
+			o.Chemistry.Shaped = append(o.Chemistry.Shaped, r)
 		case *protocol.SmithingTransformRecipe:
-			smithing = append(smithing, NewShapelessRecipe(protocol.ShapelessRecipe{
+			o.Smithing = append(o.Smithing, NewShapelessRecipe(o.reg, protocol.ShapelessRecipe{
 				Input:  []protocol.ItemDescriptorCount{recipe.Base, recipe.Addition, recipe.Template},
 				Output: []protocol.ItemStack{recipe.Result},
 				Block:  recipe.Block,
 			}))
 		case *protocol.SmithingTrimRecipe:
-			smithingTrim = append(smithingTrim, NewShapelessRecipe(protocol.ShapelessRecipe{
+			o.SmithingTrim = append(o.SmithingTrim, NewShapelessRecipe(o.reg, protocol.ShapelessRecipe{
 				Input: []protocol.ItemDescriptorCount{recipe.Base, recipe.Addition, recipe.Template},
 				Block: recipe.Block,
 			}))
 		}
 	}
+	o.RecipeBook = newRecipeBook(o.reg, pk)
 	for _, recipe := range pk.PotionRecipes {
-		potions = append(potions, NewPotionRecipe(recipe))
+		o.Potions.Potions = append(o.Potions.Potions, NewPotionRecipe(o.reg, recipe))
 	}
 	for _, recipe := range pk.PotionContainerChangeRecipes {
-		potionContainerChanges = append(potionContainerChanges, NewPotionContainerChangeRecipe(recipe))
+		o.Potions.ContainerChanges = append(o.Potions.ContainerChanges, NewPotionContainerChangeRecipe(o.reg, recipe))
 	}
-	write.NBT("output/dragonfly/server/item/recipe/furnace_data.nbt", furnace)
-	write.NBT("output/dragonfly/server/item/recipe/crafting_data.nbt", CraftingRecipes{Shaped: shaped, Shapeless: shapeless})
-	write.NBT("output/dragonfly/server/item/recipe/smithing_data.nbt", smithing)
-	write.NBT("output/dragonfly/server/item/recipe/smithing_trim_data.nbt", smithingTrim)
-	write.NBT("output/dragonfly/server/item/recipe/potion_data.nbt", PotionRecipes{Potions: potions, ContainerChanges: potionContainerChanges})
 }
 
-func HandleCreativeContent(pk *packet.CreativeContent) {
-	var groups []CreativeGroup
-	var items []CreativeItem
+// HandleUnlockedRecipes marks the recipes unlocked for a new player in the recipe book. It must be called after
+// HandleCraftingData.
+func (o *Output) HandleUnlockedRecipes(pk *packet.UnlockedRecipes) {
+	o.Warnings = append(o.Warnings, unlockRecipes(o.RecipeBook, pk)...)
+}
+
+func (o *Output) HandleTrimData(pk *packet.TrimData) {
+	o.Trim = NewTrimData(o.reg, pk)
+}
+
+func (o *Output) HandleCreativeContent(pk *packet.CreativeContent) {
+	o.Creative = CreativeContent{}
 	for _, group := range pk.Groups {
-		groups = append(groups, CreativeGroup{
+		o.Creative.Groups = append(o.Creative.Groups, CreativeGroup{
 			Category: group.Category,
 			Name:     group.Name,
-			Icon:     creativeItemFromStack(group.Icon),
+			Icon:     o.creativeItemFromStack(group.Icon),
 		})
 	}
+	// A group is only tagged as education content if it holds items and all of them are.
+	items, educationItems := make([]int, len(o.Creative.Groups)), make([]int, len(o.Creative.Groups))
 	for _, entry := range pk.Items {
-		ci := creativeItemFromStack(entry.Item)
+		ci := o.creativeItemFromStack(entry.Item)
 		ci.GroupIndex = int32(entry.GroupIndex)
-		items = append(items, ci)
+		ci.Education = o.reg.IsEducationItem(ci.Name)
+		if int(entry.GroupIndex) < len(items) {
+			items[entry.GroupIndex]++
+			if ci.Education {
+				educationItems[entry.GroupIndex]++
+			}
+		}
+		o.Creative.Items = append(o.Creative.Items, ci)
+	}
+	for i := range o.Creative.Groups {
+		o.Creative.Groups[i].Education = items[i] > 0 && items[i] == educationItems[i]
 	}
-	write.NBT("output/dragonfly/server/item/creative/creative_items.nbt", CreativeContent{groups, items})
 }
 
-func creativeItemFromStack(s protocol.ItemStack) CreativeItem {
+func (o *Output) creativeItemFromStack(s protocol.ItemStack) CreativeItem {
 	ci := CreativeItem{
-		Name: data.ItemNetworkIDToName[s.ItemType.NetworkID],
+		Name: itemName(o.reg, s.ItemType.NetworkID),
 		Meta: int16(s.ItemType.MetadataValue),
 		NBT:  s.NBTData,
 	}
@@ -103,7 +299,7 @@ func creativeItemFromStack(s protocol.ItemStack) CreativeItem {
 		if ci.Meta != 0 {
 			panic(fmt.Errorf("block item %s has non-zero metadata %d", ci.Name, ci.Meta))
 		}
-		_, props, ok := chunk.RuntimeIDToState(uint32(s.BlockRuntimeID))
+		_, props, ok := o.reg.BlockState(uint32(s.BlockRuntimeID))
 		if ok {
 			ci.BlockProperties = props
 		} else {
@@ -112,3 +308,8 @@ func creativeItemFromStack(s protocol.ItemStack) CreativeItem {
 	}
 	return ci
 }
+
+// warnf adds a warning to the Output, formatted using the format and arguments passed.
+func (o *Output) warnf(format string, a ...any) {
+	o.Warnings = append(o.Warnings, fmt.Sprintf(format, a...))
+}
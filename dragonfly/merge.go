@@ -0,0 +1,219 @@
+package dragonfly
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Merge merges the data of other, generated from a session with one or more additional experiments enabled,
+// into the Output. Items, recipes and creative entries that are present in other but not in the Output are
+// added and tagged with the experiment passed, while entries already present keep their existing tag. Warnings
+// of other that the Output does not hold yet are added as well.
+func (o *Output) Merge(other *Output, experiment string) {
+	for _, w := range other.Warnings {
+		if !slices.Contains(o.Warnings, w) {
+			o.Warnings = append(o.Warnings, w)
+		}
+	}
+	o.mergeVanillaItems(other.VanillaItems, experiment)
+	for name, id := range other.Biomes {
+		if _, ok := o.Biomes[name]; !ok {
+			o.Biomes[name] = id
+		}
+	}
+	for name, item := range other.CustomItems {
+		if _, ok := o.CustomItems[name]; !ok {
+			o.CustomItems[name] = item
+		}
+	}
+	for name, block := range other.CustomBlocks {
+		if _, ok := o.CustomBlocks[name]; !ok {
+			o.CustomBlocks[name] = block
+		}
+	}
+	for entity, properties := range other.Properties {
+		if _, ok := o.Properties[entity]; !ok {
+			o.Properties[entity] = properties
+		}
+	}
+	files := featureFiles(o.Features)
+	for _, name := range slices.Sorted(maps.Keys(other.Features)) {
+		if warning, ok := addFeature(o.Features, files, name, other.Features[name]); !ok {
+			o.Warnings = append(o.Warnings, warning)
+		}
+	}
+	for name, structure := range other.Jigsaw {
+		if _, ok := o.Jigsaw[name]; !ok {
+			o.Jigsaw[name] = structure
+		}
+	}
+	o.Dimensions.Definitions = mergeOrdered(o.Dimensions.Definitions, other.Dimensions.Definitions, func(d Dimension) string { return d.Name }, func(*Dimension) {})
+	o.Entities = mergeOrdered(o.Entities, other.Entities, func(id string) string { return id }, func(*string) {})
+	o.Commands = mergeOrdered(o.Commands, other.Commands, func(c Command) string { return c.Name }, func(*Command) {})
+	o.Cameras = mergeOrdered(o.Cameras, other.Cameras, func(c CameraPreset) string { return c.Name }, func(*CameraPreset) {})
+	o.AimAssist.Categories = mergeOrdered(o.AimAssist.Categories, other.AimAssist.Categories, func(c AimAssistCategory) string { return c.Name }, func(*AimAssistCategory) {})
+	o.AimAssist.Presets = mergeOrdered(o.AimAssist.Presets, other.AimAssist.Presets, func(p AimAssistPreset) string { return p.Identifier }, func(*AimAssistPreset) {})
+
+	o.Furnace = mergeTagged(o.Furnace, other.Furnace, experiment)
+	o.Crafting.Shaped = mergeTagged(o.Crafting.Shaped, other.Crafting.Shaped, experiment)
+	o.Crafting.Shapeless = mergeTagged(o.Crafting.Shapeless, other.Crafting.Shapeless, experiment)
+	o.Chemistry.Shaped = mergeTagged(o.Chemistry.Shaped, other.Chemistry.Shaped, experiment)
+	o.Chemistry.Shapeless = mergeTagged(o.Chemistry.Shapeless, other.Chemistry.Shapeless, experiment)
+	o.Smithing = mergeTagged(o.Smithing, other.Smithing, experiment)
+	o.SmithingTrim = mergeTagged(o.SmithingTrim, other.SmithingTrim, experiment)
+	o.Potions.Potions = mergeTagged(o.Potions.Potions, other.Potions.Potions, experiment)
+	o.Potions.ContainerChanges = mergeTagged(o.Potions.ContainerChanges, other.Potions.ContainerChanges, experiment)
+	o.RecipeBook = mergeOrdered(o.RecipeBook, other.RecipeBook, func(e RecipeBookEntry) string { return e.RecipeID }, func(e *RecipeBookEntry) {
+		e.tag(experiment)
+	})
+	o.Trim.Patterns = mergeTagged(o.Trim.Patterns, other.Trim.Patterns, experiment)
+	o.Trim.Materials = mergeTagged(o.Trim.Materials, other.Trim.Materials, experiment)
+
+	o.mergeCreative(other.Creative, experiment)
+}
+
+// mergeVanillaItems adds the items that are not yet present in the Output, tagging them with the experiment
+// passed. Runtime IDs may differ between sessions, so new items keep the runtime ID of their own session even if
+// another item already uses it, in which case the name of that item is set as RuntimeIDConflict of the new item and
+// a warning is added.
+func (o *Output) mergeVanillaItems(items map[string]VanillaItemEntry, experiment string) {
+	used := make(map[int32]string, len(o.VanillaItems))
+	for name, item := range o.VanillaItems {
+		if other, ok := used[item.RuntimeID]; !ok || name < other {
+			used[item.RuntimeID] = name
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(items)) {
+		if _, ok := o.VanillaItems[name]; ok {
+			continue
+		}
+		item := items[name]
+		item.tag(experiment)
+		if other, ok := used[item.RuntimeID]; ok {
+			o.warnf("runtime ID %d of item %s of experiment %s is also used by %s, it is only valid with the experiment enabled", item.RuntimeID, name, experiment, other)
+			item.RuntimeIDConflict = other
+		} else {
+			used[item.RuntimeID] = name
+		}
+		o.VanillaItems[name] = item
+	}
+}
+
+// creativeEntry is a CreativeItem together with the key of the group it is in, used to merge creative items of
+// sessions in which the group indices differ.
+type creativeEntry struct {
+	item  CreativeItem
+	group string
+}
+
+// mergeCreative merges the creative groups and items of other into the Output. Groups and items are matched by
+// their contents rather than their index, after which the group index of every item is recalculated.
+func (o *Output) mergeCreative(other CreativeContent, experiment string) {
+	groupKey := func(g CreativeGroup) string {
+		g.Tags = Tags{}
+		return contentKey(g)
+	}
+	entries := func(c CreativeContent) []creativeEntry {
+		e := make([]creativeEntry, 0, len(c.Items))
+		for _, item := range c.Items {
+			if int(item.GroupIndex) >= len(c.Groups) {
+				panic(fmt.Errorf("creative item %s has group index %d out of range", item.Name, item.GroupIndex))
+			}
+			e = append(e, creativeEntry{item: item, group: groupKey(c.Groups[item.GroupIndex])})
+		}
+		return e
+	}
+	items := mergeOrdered(entries(o.Creative), entries(other), func(e creativeEntry) string {
+		e.item.GroupIndex, e.item.Tags = 0, Tags{}
+		return e.group + contentKey(e.item)
+	}, func(e *creativeEntry) {
+		e.item.tag(experiment)
+	})
+	groups := mergeOrdered(o.Creative.Groups, other.Groups, groupKey, func(g *CreativeGroup) {
+		g.tag(experiment)
+	})
+
+	indices := make(map[string]int32, len(groups))
+	for i, g := range groups {
+		if _, ok := indices[groupKey(g)]; !ok {
+			indices[groupKey(g)] = int32(i)
+		}
+	}
+	o.Creative = CreativeContent{Groups: groups, Items: make([]CreativeItem, 0, len(items))}
+	for _, e := range items {
+		e.item.GroupIndex = indices[e.group]
+		o.Creative.Items = append(o.Creative.Items, e.item)
+	}
+}
+
+// tagged is implemented by pointers to types that embed Tags.
+type tagged[T any] interface {
+	*T
+	tags() *Tags
+}
+
+// mergeTagged merges the values of other into current using mergeOrdered, tagging new values with the
+// experiment passed. The Tags of values are ignored when comparing them.
+func mergeTagged[T any, P tagged[T]](current, other []T, experiment string) []T {
+	return mergeOrdered(current, other, func(v T) string {
+		*P(&v).tags() = Tags{}
+		return contentKey(v)
+	}, func(v *T) {
+		P(v).tags().tag(experiment)
+	})
+}
+
+// mergeOrdered merges the values of other into current, returning the result. Values of other for which no value
+// with the same key exists in current are passed to tag and inserted directly after the value that precedes
+// them in other, so that the order of other is kept as much as possible.
+func mergeOrdered[T any](current, other []T, key func(T) string, tag func(*T)) []T {
+	known := make(map[string]struct{}, len(current))
+	for _, v := range current {
+		known[key(v)] = struct{}{}
+	}
+	// after holds the new values that should be inserted after the value in current with a specific key, while
+	// leading holds the new values that precede all values of current in other.
+	after := make(map[string][]T)
+	var leading []T
+	var anchor string
+	anchored := false
+	added := make(map[string]struct{})
+	for _, v := range other {
+		k := key(v)
+		if _, ok := known[k]; ok {
+			anchor, anchored = k, true
+			continue
+		}
+		if _, ok := added[k]; ok {
+			continue
+		}
+		added[k] = struct{}{}
+		tag(&v)
+		if anchored {
+			after[anchor] = append(after[anchor], v)
+		} else {
+			leading = append(leading, v)
+		}
+	}
+	result := make([]T, 0, len(current)+len(added))
+	result = append(result, leading...)
+	for _, v := range current {
+		result = append(result, v)
+		k := key(v)
+		result = append(result, after[k]...)
+		delete(after, k)
+	}
+	return result
+}
+
+// contentKey returns a key for the value passed based on its contents, so that values with the same contents
+// produce the same key. JSON is used rather than NBT, because JSON encodes maps with their keys sorted.
+func contentKey(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Errorf("failed to marshal %T: %w", v, err))
+	}
+	return string(b)
+}
@@ -0,0 +1,126 @@
+package dragonfly
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestMergeOrdered(t *testing.T) {
+	tests := []struct {
+		name                 string
+		current, other, want []string
+	}{
+		{"unchanged", []string{"a", "b"}, []string{"a", "b"}, []string{"a", "b"}},
+		{"after_preceding", []string{"a", "b", "c"}, []string{"a", "x", "b", "y", "z", "c"}, []string{"a", "x", "b", "y", "z", "c"}},
+		{"leading", []string{"a", "b"}, []string{"x", "a", "b"}, []string{"x", "a", "b"}},
+		{"trailing", []string{"a", "b"}, []string{"a", "b", "x"}, []string{"a", "b", "x"}},
+		{"reordered", []string{"a", "b"}, []string{"b", "x", "a"}, []string{"a", "b", "x"}},
+		{"missing_in_other", []string{"a", "b", "c"}, []string{"c", "x"}, []string{"a", "b", "c", "x"}},
+		{"duplicates", []string{"a"}, []string{"a", "x", "x"}, []string{"a", "x"}},
+		{"empty_current", nil, []string{"x", "y"}, []string{"x", "y"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tagged []string
+			got := mergeOrdered(tt.current, tt.other, func(s string) string { return s }, func(s *string) {
+				tagged = append(tagged, *s)
+			})
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			for _, s := range tagged {
+				if slices.Contains(tt.current, s) {
+					t.Errorf("value %s of current was tagged", s)
+				}
+			}
+		})
+	}
+}
+
+func TestMergeTagged(t *testing.T) {
+	current := []TrimPattern{
+		{ItemName: "minecraft:a", PatternID: "a"},
+		{ItemName: "minecraft:b", PatternID: "b", Tags: Tags{Experiment: "older"}},
+	}
+	other := []TrimPattern{
+		{ItemName: "minecraft:a", PatternID: "a"},
+		{ItemName: "minecraft:b", PatternID: "b"},
+		{ItemName: "minecraft:c", PatternID: "c"},
+		{ItemName: "minecraft:d", PatternID: "d", Tags: Tags{Education: true}},
+	}
+	want := []TrimPattern{
+		{ItemName: "minecraft:a", PatternID: "a"},
+		{ItemName: "minecraft:b", PatternID: "b", Tags: Tags{Experiment: "older"}},
+		{ItemName: "minecraft:c", PatternID: "c", Tags: Tags{Experiment: "new"}},
+		{ItemName: "minecraft:d", PatternID: "d", Tags: Tags{Experiment: "new", Education: true}},
+	}
+	if got := mergeTagged(current, other, "new"); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	// Experiments enabling education features also tag the entries they introduce as education content.
+	if got := mergeTagged(nil, other[:1], "chemistry"); got[0].Tags != (Tags{Experiment: "chemistry", Education: true}) {
+		t.Errorf("got tags %+v for entry of chemistry experiment, want education tag", got[0].Tags)
+	}
+}
+
+func TestMergeCreative(t *testing.T) {
+	stone, wood, added := CreativeGroup{Name: "stone"}, CreativeGroup{Name: "wood"}, CreativeGroup{Name: "new"}
+	o := &Output{Creative: CreativeContent{
+		Groups: []CreativeGroup{stone, wood},
+		Items: []CreativeItem{
+			{Name: "minecraft:stone", GroupIndex: 0},
+			{Name: "minecraft:planks", GroupIndex: 1},
+		},
+	}}
+	// The other session has a new group before the existing ones, so the group indices of its items differ.
+	o.mergeCreative(CreativeContent{
+		Groups: []CreativeGroup{added, stone, wood},
+		Items: []CreativeItem{
+			{Name: "minecraft:new", GroupIndex: 0},
+			{Name: "minecraft:stone", GroupIndex: 1},
+			{Name: "minecraft:granite", GroupIndex: 1},
+			{Name: "minecraft:planks", GroupIndex: 2},
+		},
+	}, "new")
+
+	want := CreativeContent{
+		Groups: []CreativeGroup{{Name: "new", Tags: Tags{Experiment: "new"}}, stone, wood},
+		Items: []CreativeItem{
+			{Name: "minecraft:new", GroupIndex: 0, Tags: Tags{Experiment: "new"}},
+			{Name: "minecraft:stone", GroupIndex: 1},
+			{Name: "minecraft:granite", GroupIndex: 1, Tags: Tags{Experiment: "new"}},
+			{Name: "minecraft:planks", GroupIndex: 2},
+		},
+	}
+	if !reflect.DeepEqual(o.Creative, want) {
+		t.Errorf("got creative content %+v, want %+v", o.Creative, want)
+	}
+}
+
+func TestMergeVanillaItems(t *testing.T) {
+	o := &Output{VanillaItems: map[string]VanillaItemEntry{
+		"minecraft:stone": {RuntimeID: 1},
+		"minecraft:stick": {RuntimeID: 320},
+	}}
+	o.mergeVanillaItems(map[string]VanillaItemEntry{
+		"minecraft:stone":     {RuntimeID: 1},
+		"minecraft:stick":     {RuntimeID: 321},
+		"minecraft:new_sword": {RuntimeID: 320},
+		"minecraft:new_axe":   {RuntimeID: 400},
+	}, "swords")
+
+	want := map[string]VanillaItemEntry{
+		"minecraft:stone":     {RuntimeID: 1},
+		"minecraft:stick":     {RuntimeID: 320},
+		"minecraft:new_sword": {RuntimeID: 320, RuntimeIDConflict: "minecraft:stick", Tags: Tags{Experiment: "swords"}},
+		"minecraft:new_axe":   {RuntimeID: 400, Tags: Tags{Experiment: "swords"}},
+	}
+	if !reflect.DeepEqual(o.VanillaItems, want) {
+		t.Errorf("got items %+v, want %+v", o.VanillaItems, want)
+	}
+	wantWarnings := []string{"runtime ID 320 of item minecraft:new_sword of experiment swords is also used by minecraft:stick, it is only valid with the experiment enabled"}
+	if !slices.Equal(o.Warnings, wantWarnings) {
+		t.Errorf("got warnings %q, want %q", o.Warnings, wantWarnings)
+	}
+}
@@ -0,0 +1,91 @@
+package dragonfly
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/df-mc/datagen/data"
+	"github.com/sandertv/gophertunnel/minecraft"
+)
+
+// MetaCoverage holds the vanilla items and blocks that are not covered by the item meta to block state map of the
+// palette. Items listed in ItemsWithoutMeta are block items of the server, which have a runtime ID below 256, for
+// which the meta map does not resolve meta 0 to a block state, usually because the palette is outdated. Blocks
+// listed in BlocksWithoutItem are not placed by any block item of the server and are not known to lack an item,
+// such as wall signs or double slabs do.
+type MetaCoverage struct {
+	ItemsWithoutMeta  []string `json:"items_without_meta"`
+	BlocksWithoutItem []string `json:"blocks_without_item"`
+}
+
+// itemlessBlocks holds the vanilla blocks that have no item by design, such as blocks that are only placed as part
+// of another block or by the game itself. itemlessBlockPrefixes and itemlessBlockSuffixes hold the prefixes and
+// suffixes of the names of families of such blocks.
+var (
+	itemlessBlocks = []string{
+		"minecraft:beetroot", "minecraft:bubble_column", "minecraft:candle_cake", "minecraft:carrots",
+		"minecraft:cave_vines", "minecraft:cave_vines_body_with_berries", "minecraft:cave_vines_head_with_berries",
+		"minecraft:client_request_placeholder_block", "minecraft:cocoa", "minecraft:daylight_detector_inverted",
+		"minecraft:end_gateway", "minecraft:end_portal", "minecraft:fire", "minecraft:flowing_lava",
+		"minecraft:flowing_water", "minecraft:frosted_ice", "minecraft:lava", "minecraft:melon_stem",
+		"minecraft:moving_block", "minecraft:mysterious_frame", "minecraft:mysterious_frame_slot",
+		"minecraft:piston_arm_collision", "minecraft:pitcher_crop", "minecraft:portal", "minecraft:potatoes",
+		"minecraft:pumpkin_stem", "minecraft:redstone_wire", "minecraft:soul_fire", "minecraft:standing_banner",
+		"minecraft:standing_sign", "minecraft:sticky_piston_arm_collision", "minecraft:sweet_berry_bush",
+		"minecraft:torchflower_crop", "minecraft:tripwire", "minecraft:unknown", "minecraft:unlit_redstone_torch",
+		"minecraft:wall_banner", "minecraft:wall_sign", "minecraft:water", "minecraft:wheat",
+	}
+	itemlessBlockPrefixes = []string{"minecraft:double_", "minecraft:lit_", "minecraft:powered_", "minecraft:unpowered_"}
+	itemlessBlockSuffixes = []string{"_candle_cake", "_double_slab", "_standing_sign", "_wall_sign"}
+)
+
+// itemless checks if the vanilla block with the name passed has no item by design.
+func itemless(name string) bool {
+	return slices.Contains(itemlessBlocks, name) ||
+		slices.ContainsFunc(itemlessBlockPrefixes, func(prefix string) bool { return strings.HasPrefix(name, prefix) }) ||
+		slices.ContainsFunc(itemlessBlockSuffixes, func(suffix string) bool { return strings.HasSuffix(name, suffix) })
+}
+
+// newItemMetaStates returns the block states placed by items with specific meta values in the Registry passed,
+// indexed by the item name and the meta value.
+func newItemMetaStates(reg *data.Registry) map[string]map[string]map[string]any {
+	m := make(map[string]map[string]map[string]any)
+	for name, metas := range reg.ItemMetaBlockStates() {
+		m[name] = make(map[string]map[string]any, len(metas))
+		for meta, state := range metas {
+			m[name][strconv.Itoa(int(meta))] = state
+		}
+	}
+	return m
+}
+
+// newMetaCoverage checks the vanilla block items in the game data passed against the item meta to block state map
+// of the Registry passed, and the vanilla block states of the Registry against the block states that those items
+// place, listing the items and blocks that are not covered.
+func newMetaCoverage(reg *data.Registry, gameData minecraft.GameData) MetaCoverage {
+	coverage := MetaCoverage{ItemsWithoutMeta: []string{}, BlocksWithoutItem: []string{}}
+	metas, placed := reg.ItemMetaBlockStates(), make(map[string]bool)
+	for _, item := range gameData.Items {
+		if item.RuntimeID >= 256 || !strings.HasPrefix(item.Name, "minecraft:") {
+			continue
+		}
+		if _, ok := reg.ItemMetaBlockState(item.Name, 0); !ok {
+			coverage.ItemsWithoutMeta = append(coverage.ItemsWithoutMeta, item.Name)
+		}
+		for _, state := range metas[item.Name] {
+			name, _ := state["name"].(string)
+			placed[name] = true
+		}
+	}
+	blocks := make(map[string]bool)
+	for _, s := range reg.States() {
+		if !placed[s.Name] && !itemless(s.Name) && strings.HasPrefix(s.Name, "minecraft:") {
+			blocks[s.Name] = true
+		}
+	}
+	coverage.BlocksWithoutItem = append(coverage.BlocksWithoutItem, slices.Sorted(maps.Keys(blocks))...)
+	slices.Sort(coverage.ItemsWithoutMeta)
+	return coverage
+}
@@ -0,0 +1,58 @@
+package dragonfly
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+func TestNewMetaCoverage(t *testing.T) {
+	items := []protocol.ItemEntry{
+		{Name: "minecraft:stone", RuntimeID: 1},
+		{Name: "minecraft:furnace", RuntimeID: 61},
+		{Name: "minecraft:oak_slab", RuntimeID: -500},
+		// Block items without a block in the palette, such as blocks renamed after the palette was generated.
+		{Name: "minecraft:renamed_block", RuntimeID: -600},
+		// Items that are not block items are never checked.
+		{Name: "minecraft:stick", RuntimeID: 320},
+		{Name: "minecraft:oak_sign", RuntimeID: 321},
+	}
+	reg := testRegistry(t, items,
+		"minecraft:stone", "minecraft:furnace", "minecraft:lit_furnace", "minecraft:oak_slab", "minecraft:oak_double_slab",
+		"minecraft:standing_sign", "minecraft:wall_sign", "minecraft:spruce_standing_sign", "minecraft:water",
+		"minecraft:new_block", "custom:block",
+	)
+	got := newMetaCoverage(reg, minecraft.GameData{Items: items})
+	want := MetaCoverage{
+		ItemsWithoutMeta:  []string{"minecraft:renamed_block"},
+		BlocksWithoutItem: []string{"minecraft:new_block"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got coverage %+v, want %+v", got, want)
+	}
+}
+
+func TestItemless(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"minecraft:standing_sign", true},
+		{"minecraft:darkoak_wall_sign", true},
+		{"minecraft:lit_blast_furnace", true},
+		{"minecraft:unpowered_repeater", true},
+		{"minecraft:double_cut_copper_slab", true},
+		{"minecraft:cobblestone_double_slab", true},
+		{"minecraft:red_candle_cake", true},
+		{"minecraft:stone", false},
+		{"minecraft:oak_slab", false},
+		{"minecraft:oak_hanging_sign", false},
+	}
+	for _, tt := range tests {
+		if got := itemless(tt.name); got != tt.want {
+			t.Errorf("itemless(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
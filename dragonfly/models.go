@@ -3,16 +3,50 @@ package dragonfly
 import (
 	"fmt"
 	"math"
+	"slices"
 
 	"github.com/df-mc/datagen/data"
-	"github.com/df-mc/dragonfly/server/world/chunk"
 	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
 )
 
 const (
 	CurrentBlockVersion = (1 << 24) | (21 << 16) | (20 << 8) | 6 // 18158598
 )
 
+// Tags holds the tags set on vanilla items, recipes and creative entries that are only available under specific
+// conditions, so that dragonfly can exclude them when these conditions are not met.
+type Tags struct {
+	// Experiment is the experiment, or comma separated list of experiments, that introduced the entry. It is
+	// empty for entries that are always available.
+	Experiment string `nbt:"experiment,omitempty"`
+	// Education is true if the entry is only available with education features enabled.
+	Education bool `nbt:"education,omitempty"`
+}
+
+// tags returns a pointer to the Tags, so that the tags of any type embedding Tags may be changed.
+func (t *Tags) tags() *Tags {
+	return t
+}
+
+// tag tags an entry as introduced by the experiment passed. If the experiment enables education features, the
+// entry is also tagged as education content.
+func (t *Tags) tag(experiment string) {
+	t.Experiment = experiment
+	t.Education = t.Education || data.IsEducationExperiment(experiment)
+}
+
+// ActorIdentifiers represents the structure of the NBT sent in the AvailableActorIdentifiers packet.
+type ActorIdentifiers struct {
+	IDList []ActorIdentifier `nbt:"idlist"`
+}
+
+// ActorIdentifier represents a single entity in the NBT sent in the AvailableActorIdentifiers packet.
+type ActorIdentifier struct {
+	ID        string `nbt:"id"`
+	RuntimeID int32  `nbt:"rid"`
+}
+
 // CraftingRecipes represents the structure for crafting_data.nbt that dragonfly uses.
 type CraftingRecipes struct {
 	Shaped    []ShapedRecipe    `nbt:"shaped"`
@@ -30,6 +64,7 @@ type CreativeGroup struct {
 	Category int32        `nbt:"category"`
 	Name     string       `nbt:"name"`
 	Icon     CreativeItem `nbt:"icon"`
+	Tags
 }
 
 // CreativeItem represents the structure of a creative item that dragonfly reads from creative_items.nbt.
@@ -38,14 +73,21 @@ type CreativeItem struct {
 	Meta            int16          `nbt:"meta,omitempty"`
 	NBT             map[string]any `nbt:"nbt,omitempty"`
 	BlockProperties map[string]any `nbt:"block_properties,omitempty"`
-	GroupIndex      int32          `nbt:"group_index,omitempty""`
+	GroupIndex      int32          `nbt:"group_index,omitempty"`
+	Tags
 }
 
+// VanillaItemEntry represents a single item in vanilla_items.nbt and custom_items.nbt. RuntimeID is always the
+// runtime ID that the server sent for the item. RuntimeIDConflict is only set for items introduced by an experiment
+// of which the runtime ID is used by another item without the experiment, and holds the name of that item. Such
+// runtime IDs are only valid with the experiment enabled, as the server assigns runtime IDs per world.
 type VanillaItemEntry struct {
-	RuntimeID      int32          `nbt:"runtime_id"`
-	ComponentBased bool           `nbt:"component_based"`
-	Version        int32          `nbt:"version"`
-	Data           map[string]any `nbt:"data,omitempty"`
+	RuntimeID         int32          `json:"runtime_id" nbt:"runtime_id"`
+	RuntimeIDConflict string         `json:"runtime_id_conflict,omitempty" nbt:"runtime_id_conflict,omitempty"`
+	ComponentBased    bool           `json:"component_based" nbt:"component_based"`
+	Version           int32          `json:"version" nbt:"version"`
+	Data              map[string]any `json:"data,omitempty" nbt:"data,omitempty"`
+	Tags
 }
 
 // RecipeInputItem represents the structure of an input item in a recipe.
@@ -57,6 +99,12 @@ type RecipeInputItem struct {
 	Tag   string         `nbt:"tag,omitempty"`
 }
 
+// education checks if the input item is only available with education features enabled according to the
+// Registry passed.
+func (i RecipeInputItem) education(reg *data.Registry) bool {
+	return reg.IsEducationItem(i.Name) || data.IsEducationTag(i.Tag)
+}
+
 // RecipeOutputItem represents the structure of an output item in a recipe.
 type RecipeOutputItem struct {
 	Name    string         `nbt:"name"`
@@ -66,28 +114,36 @@ type RecipeOutputItem struct {
 	NBTData map[string]any `nbt:"data,omitempty"`
 }
 
+// education checks if the output item is only available with education features enabled according to the
+// Registry passed.
+func (i RecipeOutputItem) education(reg *data.Registry) bool {
+	return reg.IsEducationItem(i.Name)
+}
+
 // FurnaceRecipe represents the structure of a shaped recipe in dragonfly, used in crafting_data.nbt.
 type FurnaceRecipe struct {
 	Input  RecipeInputItem  `nbt:"input,omitempty"`
 	Output RecipeOutputItem `nbt:"output,omitempty"`
 	Block  string           `nbt:"block,omitempty"`
+	Tags
 }
 
 // NewFurnaceRecipe creates a new FurnaceRecipe from a protocol.FurnaceRecipe. It converts the input and output
 // items to the RecipeInputItem and RecipeOutputItem structures.
-func NewFurnaceRecipe(recipe protocol.FurnaceRecipe) FurnaceRecipe {
-	return FurnaceRecipe{
-		Input: newInputItem(protocol.ItemDescriptorCount{
+func NewFurnaceRecipe(reg *data.Registry, recipe protocol.FurnaceRecipe) FurnaceRecipe {
+	r := FurnaceRecipe{
+		Input: newInputItem(reg, protocol.ItemDescriptorCount{
 			Descriptor: &protocol.DefaultItemDescriptor{
 				NetworkID:     int16(recipe.InputType.NetworkID),
 				MetadataValue: int16(recipe.InputType.MetadataValue),
 			},
 			Count: 1,
 		}, false),
-		Output: newOutputItem(recipe.Output),
+		Output: newOutputItem(reg, recipe.Output),
 		Block:  recipe.Block,
 	}
-
+	r.Education = educationRecipe(reg, r.Block, []RecipeInputItem{r.Input}, []RecipeOutputItem{r.Output})
+	return r
 }
 
 // ShapedRecipe represents the structure of a shaped recipe in dragonfly, used in crafting_data.nbt.
@@ -98,18 +154,19 @@ type ShapedRecipe struct {
 	Width    int32              `nbt:"width,omitempty"`
 	Height   int32              `nbt:"height,omitempty"`
 	Priority int32              `nbt:"priority,omitempty"`
+	Tags
 }
 
 // NewShapedRecipe creates a new ShapedRecipe from a protocol.ShapedRecipe. It converts the input and output
 // items to the RecipeInputItem and RecipeOutputItem structures.
-func NewShapedRecipe(recipe protocol.ShapedRecipe) ShapedRecipe {
+func NewShapedRecipe(reg *data.Registry, recipe protocol.ShapedRecipe) ShapedRecipe {
 	var input []RecipeInputItem
 	for _, item := range recipe.Input {
-		input = append(input, newInputItem(item, true))
+		input = append(input, newInputItem(reg, item, true))
 	}
 	var output []RecipeOutputItem
 	for _, item := range recipe.Output {
-		output = append(output, newOutputItem(item))
+		output = append(output, newOutputItem(reg, item))
 	}
 	return ShapedRecipe{
 		Input:    input,
@@ -118,8 +175,8 @@ func NewShapedRecipe(recipe protocol.ShapedRecipe) ShapedRecipe {
 		Width:    recipe.Width,
 		Height:   recipe.Height,
 		Priority: recipe.Priority,
+		Tags:     Tags{Education: educationRecipe(reg, recipe.Block, input, output)},
 	}
-
 }
 
 // ShapelessRecipe represents the structure of a shapeless recipe in dragonfly, used in crafting_data.nbt but
@@ -129,24 +186,26 @@ type ShapelessRecipe struct {
 	Output   []RecipeOutputItem `nbt:"output,omitempty"`
 	Block    string             `nbt:"block,omitempty"`
 	Priority int32              `nbt:"priority,omitempty"`
+	Tags
 }
 
 // NewShapelessRecipe creates a new ShapelessRecipe from a protocol.ShapelessRecipe. It converts the input and
 // output items to the RecipeInputItem and RecipeOutputItem structures.
-func NewShapelessRecipe(recipe protocol.ShapelessRecipe) ShapelessRecipe {
+func NewShapelessRecipe(reg *data.Registry, recipe protocol.ShapelessRecipe) ShapelessRecipe {
 	var input []RecipeInputItem
 	for _, item := range recipe.Input {
-		input = append(input, newInputItem(item, false))
+		input = append(input, newInputItem(reg, item, false))
 	}
 	var output []RecipeOutputItem
 	for _, item := range recipe.Output {
-		output = append(output, newOutputItem(item))
+		output = append(output, newOutputItem(reg, item))
 	}
 	return ShapelessRecipe{
 		Input:    input,
 		Output:   output,
 		Block:    recipe.Block,
 		Priority: recipe.Priority,
+		Tags:     Tags{Education: educationRecipe(reg, recipe.Block, input, output)},
 	}
 }
 
@@ -159,9 +218,10 @@ type PotionRecipe struct {
 	Input   RecipeInputItem  `nbt:"input,omitempty"`
 	Reagent RecipeInputItem  `nbt:"reagent,omitempty"`
 	Output  RecipeOutputItem `nbt:"output,omitempty"`
+	Tags
 }
 
-func NewPotionRecipe(recipe protocol.PotionRecipe) PotionRecipe {
+func NewPotionRecipe(reg *data.Registry, recipe protocol.PotionRecipe) PotionRecipe {
 	input := protocol.ItemDescriptorCount{
 		Descriptor: &protocol.DefaultItemDescriptor{
 			NetworkID:     int16(recipe.InputPotionID),
@@ -183,37 +243,63 @@ func NewPotionRecipe(recipe protocol.PotionRecipe) PotionRecipe {
 		},
 		Count: 1,
 	}
-	return PotionRecipe{
-		Input:   newInputItem(input, false),
-		Reagent: newInputItem(reagent, false),
-		Output:  newOutputItem(output),
+	r := PotionRecipe{
+		Input:   newInputItem(reg, input, false),
+		Reagent: newInputItem(reg, reagent, false),
+		Output:  newOutputItem(reg, output),
 	}
+	r.Education = educationRecipe(reg, "", []RecipeInputItem{r.Input, r.Reagent}, []RecipeOutputItem{r.Output})
+	return r
 }
 
 type PotionContainerChangeRecipe struct {
 	Input   string          `nbt:"input,omitempty"`
 	Reagent RecipeInputItem `nbt:"reagent,omitempty"`
 	Output  string          `nbt:"output,omitempty"`
+	Tags
 }
 
-func NewPotionContainerChangeRecipe(recipe protocol.PotionContainerChangeRecipe) PotionContainerChangeRecipe {
+func NewPotionContainerChangeRecipe(reg *data.Registry, recipe protocol.PotionContainerChangeRecipe) PotionContainerChangeRecipe {
 	reagent := protocol.ItemDescriptorCount{
 		Descriptor: &protocol.DefaultItemDescriptor{
 			NetworkID: int16(recipe.ReagentItemID),
 		},
 		Count: 1,
 	}
-	return PotionContainerChangeRecipe{
-		Input:   data.ItemNetworkIDToName[recipe.InputItemID],
-		Reagent: newInputItem(reagent, false),
-		Output:  data.ItemNetworkIDToName[recipe.OutputItemID],
+	r := PotionContainerChangeRecipe{
+		Input:   itemName(reg, recipe.InputItemID),
+		Reagent: newInputItem(reg, reagent, false),
+		Output:  itemName(reg, recipe.OutputItemID),
 	}
+	r.Education = reg.IsEducationItem(r.Input) || reg.IsEducationItem(r.Output) || r.Reagent.education(reg)
+	return r
+}
+
+// itemName returns the name of the item with the network ID passed, or an empty string if the network ID is 0,
+// which is used for empty item stacks. It panics if no item with the network ID exists.
+func itemName(reg *data.Registry, networkID int32) string {
+	if networkID == 0 {
+		return ""
+	}
+	name, ok := reg.ItemName(networkID)
+	if !ok {
+		panic(fmt.Errorf("no item with network ID %d", networkID))
+	}
+	return name
+}
+
+// educationRecipe checks if a recipe crafted in the block passed with the input and output items passed is only
+// available with education features enabled according to the Registry passed.
+func educationRecipe(reg *data.Registry, block string, input []RecipeInputItem, output []RecipeOutputItem) bool {
+	return data.IsEducationBlock(block) ||
+		slices.ContainsFunc(input, func(i RecipeInputItem) bool { return i.education(reg) }) ||
+		slices.ContainsFunc(output, func(i RecipeOutputItem) bool { return i.education(reg) })
 }
 
 // newInputItem returns a new RecipeInputItem from an ItemDescriptorCount. If includeAir is true, the item
 // will return an air item if the descriptor is invalid. If includeAir is false, the function will panic if
 // the descriptor is invalid.
-func newInputItem(input protocol.ItemDescriptorCount, includeAir bool) RecipeInputItem {
+func newInputItem(reg *data.Registry, input protocol.ItemDescriptorCount, includeAir bool) RecipeInputItem {
 	item := RecipeInputItem{Count: input.Count}
 	switch it := input.Descriptor.(type) {
 	case *protocol.InvalidItemDescriptor:
@@ -222,7 +308,7 @@ func newInputItem(input protocol.ItemDescriptorCount, includeAir bool) RecipeInp
 		}
 		panic("invalid item descriptor")
 	case *protocol.DefaultItemDescriptor:
-		item.Name = data.ItemNetworkIDToName[int32(it.NetworkID)]
+		item.Name = itemName(reg, int32(it.NetworkID))
 		item.Meta = int32(it.MetadataValue)
 	case *protocol.MoLangItemDescriptor:
 		panic("unsupported molang item descriptor")
@@ -239,36 +325,85 @@ func newInputItem(input protocol.ItemDescriptorCount, includeAir bool) RecipeInp
 	if item.Meta == int32(math.MaxInt16) {
 		return item
 	}
-	if itemMetas, ok := data.ItemMetaToBlockState[item.Name]; ok {
-		if state, ok := itemMetas[item.Meta]; ok {
-			item.Meta = 0
-			item.State = state
-		}
+	if state, ok := reg.ItemMetaBlockState(item.Name, item.Meta); ok {
+		item.Meta = 0
+		item.State = state
 	}
 	return item
 }
 
 // newOutputItem returns a new RecipeOutputItem from an ItemStack. It converts the ItemStack to a
 // RecipeOutputItem, setting the name, meta, count and NBT data.
-func newOutputItem(output protocol.ItemStack) RecipeOutputItem {
+func newOutputItem(reg *data.Registry, output protocol.ItemStack) RecipeOutputItem {
 	item := RecipeOutputItem{
-		Name:    data.ItemNetworkIDToName[output.NetworkID],
+		Name:    itemName(reg, output.NetworkID),
 		Meta:    int32(output.MetadataValue),
 		Count:   int16(output.Count),
 		NBTData: output.NBTData,
 	}
-	name, props, ok := chunk.RuntimeIDToState(uint32(output.BlockRuntimeID))
+	name, props, ok := reg.BlockState(uint32(output.BlockRuntimeID))
 	if ok {
-		if itemMetas, ok := data.ItemMetaToBlockState[item.Name]; ok {
-			if _, ok := itemMetas[item.Meta]; ok {
-				item.Meta = 0
-				item.State = map[string]any{
-					"name":    name,
-					"states":  props,
-					"version": int32(CurrentBlockVersion),
-				}
+		if _, ok := reg.ItemMetaBlockState(item.Name, item.Meta); ok {
+			item.Meta = 0
+			item.State = map[string]any{
+				"name":    name,
+				"states":  props,
+				"version": int32(CurrentBlockVersion),
 			}
 		}
 	}
 	return item
 }
+
+// TrimData represents the structure of trim_data.nbt, which holds the armour trim patterns and materials that
+// dragonfly uses to validate armour trims.
+type TrimData struct {
+	Patterns  []TrimPattern  `nbt:"patterns"`
+	Materials []TrimMaterial `nbt:"materials"`
+}
+
+// TrimPattern represents a single armour trim pattern along with the smithing template item that applies it.
+type TrimPattern struct {
+	ItemName  string `nbt:"item_name"`
+	PatternID string `nbt:"pattern_id"`
+	Tags
+}
+
+// TrimMaterial represents a single armour trim material along with the item that applies it and the colour code
+// used to format text of the trim.
+type TrimMaterial struct {
+	MaterialID string `nbt:"material_id"`
+	Colour     string `nbt:"colour"`
+	ItemName   string `nbt:"item_name"`
+	Tags
+}
+
+// NewTrimData creates the TrimData from the TrimData packet passed, tagging education items using the Registry
+// passed.
+func NewTrimData(reg *data.Registry, pk *packet.TrimData) TrimData {
+	t := TrimData{Patterns: []TrimPattern{}, Materials: []TrimMaterial{}}
+	for _, p := range pk.Patterns {
+		t.Patterns = append(t.Patterns, TrimPattern{
+			ItemName:  p.ItemName,
+			PatternID: p.PatternID,
+			Tags:      Tags{Education: reg.IsEducationItem(p.ItemName)},
+		})
+	}
+	for _, m := range pk.Materials {
+		t.Materials = append(t.Materials, TrimMaterial{
+			MaterialID: m.MaterialID,
+			Colour:     m.Colour,
+			ItemName:   m.ItemName,
+			Tags:       Tags{Education: reg.IsEducationItem(m.ItemName)},
+		})
+	}
+	return t
+}
+
+// CustomBlock represents a single custom block in custom_blocks.json and custom_blocks.nbt. Properties holds the
+// block properties as sent by the server, including its components, and States holds all states of the block in
+// the order that they were assigned runtime IDs.
+type CustomBlock struct {
+	Properties map[string]any   `json:"properties" nbt:"properties"`
+	States     []map[string]any `json:"states" nbt:"states"`
+}
@@ -0,0 +1,65 @@
+package dragonfly
+
+import (
+	"fmt"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// EntityProperty represents a single data-driven property of an entity in entity_properties.json and
+// entity_properties.nbt. Type is "int", "float", "bool" or "enum". Min and Max are only set for int and float
+// properties, holding an int32 or float32 respectively, and Enum is only set for enum properties. The index of a
+// property in the list of its entity is the index used to send its value.
+type EntityProperty struct {
+	Name string   `json:"name" nbt:"name"`
+	Type string   `json:"type" nbt:"type"`
+	Min  any      `json:"min,omitempty" nbt:"min,omitempty"`
+	Max  any      `json:"max,omitempty" nbt:"max,omitempty"`
+	Enum []string `json:"enum,omitempty" nbt:"enum,omitempty"`
+}
+
+// entityPropertyTypes holds the names of the entity property types, indexed by their value.
+var entityPropertyTypes = []string{"int", "float", "bool", "enum"}
+
+// newEntityProperties decodes the property data of the SyncActorProperty packet passed, returning the name of the
+// entity and its properties in the order sent.
+func newEntityProperties(pk *packet.SyncActorProperty) (string, []EntityProperty) {
+	entity := field[string](pk.PropertyData, "type", "entity properties")
+	list := field[[]any](pk.PropertyData, "properties", "entity properties of "+entity)
+	properties := make([]EntityProperty, 0, len(list))
+	for _, v := range list {
+		m, ok := v.(map[string]any)
+		if !ok {
+			panic(fmt.Errorf("property of entity %s has unexpected type %T", entity, v))
+		}
+		p := EntityProperty{Name: field[string](m, "name", "property of entity "+entity)}
+		kind := fmt.Sprintf("property %s of entity %s", p.Name, entity)
+		p.Type = lookup(entityPropertyTypes, uint32(field[int32](m, "type", kind)), "entity property type")
+		switch p.Type {
+		case "int":
+			p.Min, p.Max = field[int32](m, "min", kind), field[int32](m, "max", kind)
+		case "float":
+			p.Min, p.Max = field[float32](m, "min", kind), field[float32](m, "max", kind)
+		case "enum":
+			for _, value := range field[[]any](m, "enum", kind) {
+				s, ok := value.(string)
+				if !ok {
+					panic(fmt.Errorf("enum value of %s has unexpected type %T", kind, value))
+				}
+				p.Enum = append(p.Enum, s)
+			}
+		}
+		properties = append(properties, p)
+	}
+	return entity, properties
+}
+
+// field returns the value of the field with the key passed in the NBT compound m, panicking if it is not present
+// or not of type T.
+func field[T any](m map[string]any, key, kind string) T {
+	v, ok := m[key].(T)
+	if !ok {
+		panic(fmt.Errorf("%s has field %q of unexpected type %T", kind, key, m[key]))
+	}
+	return v
+}
@@ -0,0 +1,89 @@
+package dragonfly
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/df-mc/datagen/data"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// RecipeBookEntry represents a single recipe in recipe_book.nbt, which holds the state of the recipe book of a
+// new player. UnlockContext is only set for crafting recipes, and UnlockIngredients only if the context is
+// "none", in which case the recipe is unlocked by obtaining one of the ingredients.
+type RecipeBookEntry struct {
+	RecipeID          string            `nbt:"recipe_id"`
+	Type              string            `nbt:"type"`
+	InitiallyUnlocked bool              `nbt:"initially_unlocked"`
+	UnlockContext     string            `nbt:"unlock_context,omitempty"`
+	UnlockIngredients []RecipeInputItem `nbt:"unlock_ingredients,omitempty"`
+	Tags
+}
+
+// recipeUnlockContexts holds the names of the contexts in which recipes are unlocked, indexed by their value.
+var recipeUnlockContexts = []string{"none", "always_unlocked", "player_in_water", "player_has_many_items"}
+
+// newRecipeBook creates an entry for every recipe in the CraftingData packet passed that has a recipe ID, sorted
+// by the recipe ID. None of the recipes are unlocked yet.
+func newRecipeBook(reg *data.Registry, pk *packet.CraftingData) []RecipeBookEntry {
+	var entries []RecipeBookEntry
+	for _, recipe := range pk.Recipes {
+		var entry RecipeBookEntry
+		var unlock *protocol.RecipeUnlockRequirement
+		switch r := recipe.(type) {
+		case *protocol.ShapedRecipe:
+			entry, unlock = RecipeBookEntry{RecipeID: r.RecipeID, Type: "shaped"}, &r.UnlockRequirement
+		case *protocol.ShapelessRecipe:
+			entry, unlock = RecipeBookEntry{RecipeID: r.RecipeID, Type: "shapeless"}, &r.UnlockRequirement
+		case *protocol.ShapedChemistryRecipe:
+			entry, unlock = RecipeBookEntry{RecipeID: r.RecipeID, Type: "shaped_chemistry"}, &r.UnlockRequirement
+		case *protocol.ShapelessChemistryRecipe:
+			entry, unlock = RecipeBookEntry{RecipeID: r.RecipeID, Type: "shapeless_chemistry"}, &r.UnlockRequirement
+		case *protocol.ShulkerBoxRecipe:
+			entry, unlock = RecipeBookEntry{RecipeID: r.RecipeID, Type: "shulker_box"}, &r.UnlockRequirement
+		case *protocol.SmithingTransformRecipe:
+			entry = RecipeBookEntry{RecipeID: r.RecipeID, Type: "smithing"}
+		case *protocol.SmithingTrimRecipe:
+			entry = RecipeBookEntry{RecipeID: r.RecipeID, Type: "smithing_trim"}
+		default:
+			continue
+		}
+		if unlock != nil {
+			entry.UnlockContext = lookup(recipeUnlockContexts, unlock.Context, "recipe unlock context")
+			for _, ingredient := range unlock.Ingredients {
+				entry.UnlockIngredients = append(entry.UnlockIngredients, newInputItem(reg, ingredient, false))
+			}
+			entry.Education = slices.ContainsFunc(entry.UnlockIngredients, func(i RecipeInputItem) bool { return i.education(reg) })
+		}
+		entry.Education = entry.Education || strings.Contains(entry.Type, "chemistry")
+		entries = append(entries, entry)
+	}
+	slices.SortStableFunc(entries, func(a, b RecipeBookEntry) int {
+		return strings.Compare(a.RecipeID, b.RecipeID)
+	})
+	return entries
+}
+
+// unlockRecipes marks the recipes in the UnlockedRecipes packet passed as initially unlocked, returning a warning
+// for every recipe that is not present in the entries. Packets that do not list the recipes initially unlocked,
+// such as those sent when a player unlocks a recipe during the game, are skipped with a warning.
+func unlockRecipes(entries []RecipeBookEntry, pk *packet.UnlockedRecipes) (warnings []string) {
+	if pk.UnlockType != packet.UnlockedRecipesTypeInitiallyUnlocked {
+		return []string{fmt.Sprintf("skipped unlocked recipes of unexpected type %d", pk.UnlockType)}
+	}
+	indices := make(map[string]int, len(entries))
+	for i, entry := range entries {
+		indices[entry.RecipeID] = i
+	}
+	for _, id := range pk.Recipes {
+		i, ok := indices[id]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("unlocked recipe %s is not present in the crafting data", id))
+			continue
+		}
+		entries[i].InitiallyUnlocked = true
+	}
+	return warnings
+}
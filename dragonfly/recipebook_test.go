@@ -0,0 +1,55 @@
+package dragonfly
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+func TestUnlockRecipes(t *testing.T) {
+	tests := []struct {
+		name     string
+		pk       *packet.UnlockedRecipes
+		unlocked []bool
+		warnings []string
+	}{
+		{
+			name:     "initially_unlocked",
+			pk:       &packet.UnlockedRecipes{UnlockType: packet.UnlockedRecipesTypeInitiallyUnlocked, Recipes: []string{"minecraft:a", "minecraft:c"}},
+			unlocked: []bool{true, false, true},
+		},
+		{
+			name:     "missing",
+			pk:       &packet.UnlockedRecipes{UnlockType: packet.UnlockedRecipesTypeInitiallyUnlocked, Recipes: []string{"minecraft:b", "minecraft:d"}},
+			unlocked: []bool{false, true, false},
+			warnings: []string{"unlocked recipe minecraft:d is not present in the crafting data"},
+		},
+		{
+			name:     "newly_unlocked",
+			pk:       &packet.UnlockedRecipes{UnlockType: packet.UnlockedRecipesTypeNewlyUnlocked, Recipes: []string{"minecraft:a"}},
+			unlocked: []bool{false, false, false},
+			warnings: []string{"skipped unlocked recipes of unexpected type 2"},
+		},
+		{
+			name:     "remove_all_unlocked",
+			pk:       &packet.UnlockedRecipes{UnlockType: packet.UnlockedRecipesTypeRemoveAllUnlocked},
+			unlocked: []bool{false, false, false},
+			warnings: []string{"skipped unlocked recipes of unexpected type 4"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := []RecipeBookEntry{{RecipeID: "minecraft:a"}, {RecipeID: "minecraft:b"}, {RecipeID: "minecraft:c"}}
+			warnings := unlockRecipes(entries, tt.pk)
+			for i, entry := range entries {
+				if entry.InitiallyUnlocked != tt.unlocked[i] {
+					t.Errorf("recipe %s initially unlocked: %v, want %v", entry.RecipeID, entry.InitiallyUnlocked, tt.unlocked[i])
+				}
+			}
+			if !slices.Equal(warnings, tt.warnings) {
+				t.Errorf("got warnings %q, want %q", warnings, tt.warnings)
+			}
+		})
+	}
+}
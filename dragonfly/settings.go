@@ -0,0 +1,81 @@
+package dragonfly
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sandertv/gophertunnel/minecraft"
+)
+
+// GameSettings represents the structure of game_settings.json and game_settings.nbt, which hold the world and
+// game settings that the server sent in the StartGame packet for a newly generated world.
+type GameSettings struct {
+	BaseGameVersion              string           `json:"base_game_version" nbt:"base_game_version"`
+	ChunkRadius                  int32            `json:"chunk_radius" nbt:"chunk_radius"`
+	ServerAuthoritativeInventory bool             `json:"server_authoritative_inventory" nbt:"server_authoritative_inventory"`
+	PlayerPermissions            int32            `json:"player_permissions" nbt:"player_permissions"`
+	WorldGameMode                int32            `json:"world_game_mode" nbt:"world_game_mode"`
+	Difficulty                   int32            `json:"difficulty" nbt:"difficulty"`
+	MovementSettings             MovementSettings `json:"movement_settings" nbt:"movement_settings"`
+	GameRules                    []GameRule       `json:"game_rules" nbt:"game_rules"`
+	Experiments                  []string         `json:"experiments" nbt:"experiments"`
+}
+
+// MovementSettings represents the player movement settings in GameSettings.
+type MovementSettings struct {
+	RewindHistorySize                int32 `json:"rewind_history_size" nbt:"rewind_history_size"`
+	ServerAuthoritativeBlockBreaking bool  `json:"server_authoritative_block_breaking" nbt:"server_authoritative_block_breaking"`
+}
+
+// GameRule represents a single game rule in GameSettings with its default value. Type is either "bool", "int"
+// or "float", and Default holds a value of the corresponding Go type.
+type GameRule struct {
+	Name     string `json:"name" nbt:"name"`
+	Type     string `json:"type" nbt:"type"`
+	Default  any    `json:"default" nbt:"default"`
+	Editable bool   `json:"editable" nbt:"editable"`
+}
+
+// newGameSettings creates the GameSettings from the game data passed. Game rules are sorted by their name.
+func newGameSettings(gameData minecraft.GameData) GameSettings {
+	settings := GameSettings{
+		BaseGameVersion:              gameData.BaseGameVersion,
+		ChunkRadius:                  gameData.ChunkRadius,
+		ServerAuthoritativeInventory: gameData.ServerAuthoritativeInventory,
+		PlayerPermissions:            gameData.PlayerPermissions,
+		WorldGameMode:                gameData.WorldGameMode,
+		Difficulty:                   gameData.Difficulty,
+		MovementSettings: MovementSettings{
+			RewindHistorySize:                gameData.PlayerMovementSettings.RewindHistorySize,
+			ServerAuthoritativeBlockBreaking: gameData.PlayerMovementSettings.ServerAuthoritativeBlockBreaking,
+		},
+		GameRules:   []GameRule{},
+		Experiments: []string{},
+	}
+	for _, rule := range gameData.GameRules {
+		r := GameRule{Name: rule.Name, Editable: rule.CanBeModifiedByPlayer}
+		switch v := rule.Value.(type) {
+		case bool:
+			r.Type, r.Default = "bool", v
+		case uint32:
+			// NBT has no unsigned integer types, so the value is stored as an int32.
+			r.Type, r.Default = "int", int32(v)
+		case float32:
+			r.Type, r.Default = "float", v
+		default:
+			panic(fmt.Errorf("game rule %s has unknown value type %T", rule.Name, v))
+		}
+		settings.GameRules = append(settings.GameRules, r)
+	}
+	slices.SortFunc(settings.GameRules, func(a, b GameRule) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	for _, experiment := range gameData.Experiments {
+		if experiment.Enabled {
+			settings.Experiments = append(settings.Experiments, experiment.Name)
+		}
+	}
+	slices.Sort(settings.Experiments)
+	return settings
+}
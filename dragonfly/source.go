@@ -0,0 +1,257 @@
+package dragonfly
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// sourcePackage is the name of the package that all generated Go source files are part of.
+const sourcePackage = "vanilla"
+
+var (
+	// itemsTemplate is the template used to generate vanilla/items.go from the vanilla items.
+	itemsTemplate = newSourceTemplate(`package {{.Package}}
+
+// Item is a vanilla item as it was sent by the server in the item registry.
+type Item struct {
+	Name           string
+	RuntimeID      int32
+	ComponentBased bool
+	Version        int32
+	// Experiment is the experiment that must be enabled for the item to be available. It is empty for items
+	// that are always available.
+	Experiment string
+	// RuntimeIDConflict is the name of the item using the same runtime ID without the experiment of the item
+	// enabled, if any. The runtime ID of the item is then only valid with its experiment enabled.
+	RuntimeIDConflict string
+}
+
+// Items holds all vanilla items, sorted by their runtime ID.
+var Items = []Item{
+{{- range .Items}}
+	{Name: {{printf "%q" .Name}}, RuntimeID: {{.RuntimeID}}, ComponentBased: {{.ComponentBased}}, Version: {{.Version}}{{with .Experiment}}, Experiment: {{printf "%q" .}}{{end}}{{with .RuntimeIDConflict}}, RuntimeIDConflict: {{printf "%q" .}}{{end}}},
+{{- end}}
+}
+`)
+	// biomesTemplate is the template used to generate vanilla/biomes.go from the biome definitions.
+	biomesTemplate = newSourceTemplate(`package {{.Package}}
+
+// Biome IDs of all vanilla biomes, as found in the biome definition list.
+const (
+{{- range .Biomes}}
+	Biome{{ident .Name}} = {{.ID}}
+{{- end}}
+)
+
+// BiomeNames maps the ID of each vanilla biome to its name.
+var BiomeNames = map[int]string{
+{{- range .Biomes}}
+	Biome{{ident .Name}}: {{printf "%q" .Name}},
+{{- end}}
+}
+`)
+	// entitiesTemplate is the template used to generate vanilla/entities.go from the actor identifiers.
+	entitiesTemplate = newSourceTemplate(`package {{.Package}}
+
+// Identifiers of all vanilla entities, as found in the available actor identifiers.
+const (
+{{- range .Entities}}
+	Entity{{ident .}} = {{printf "%q" .}}
+{{- end}}
+)
+
+// Entities holds the identifiers of all vanilla entities, sorted by their legacy runtime ID.
+var Entities = []string{
+{{- range .Entities}}
+	Entity{{ident .}},
+{{- end}}
+}
+`)
+	// commandsTemplate is the template used to generate vanilla/commands.go from the available commands.
+	commandsTemplate = newSourceTemplate(`package {{.Package}}
+
+// Command is a vanilla command with all of its overloads.
+type Command struct {
+	Name            string
+	Description     string
+	Aliases         []string
+	PermissionLevel string
+	Overloads       []Overload
+}
+
+// Overload is a single way in which a Command may be executed.
+type Overload struct {
+	Parameters []Parameter
+}
+
+// Parameter is a parameter of an Overload. Type is the name of a basic type, such as "int" or "target", or
+// "enum" or "soft_enum", in which case EnumName and EnumValues hold the name and the values of the enum.
+type Parameter struct {
+	Name       string
+	Type       string
+	Optional   bool
+	Suffix     string
+	EnumName   string
+	EnumValues []string
+}
+
+// Commands holds all vanilla commands, sorted by their name.
+var Commands = []Command{
+{{- range .Commands}}
+	{
+		Name:            {{printf "%q" .Name}},
+		Description:     {{printf "%q" .Description}},
+		{{- with .Aliases}}
+		Aliases:         {{printf "%#v" .}},
+		{{- end}}
+		PermissionLevel: {{printf "%q" .PermissionLevel}},
+		Overloads: []Overload{
+		{{- range .Overloads}}
+			{Parameters: []Parameter{
+			{{- range .Parameters}}
+				{Name: {{printf "%q" .Name}}, Type: {{printf "%q" .Type}}
+				{{- if .Optional}}, Optional: true{{end}}
+				{{- with .Suffix}}, Suffix: {{printf "%q" .}}{{end}}
+				{{- with .Enum}}, EnumName: {{printf "%q" .Name}}, EnumValues: {{printf "%#v" .Values}}{{end}}},
+			{{- end}}
+			}},
+		{{- end}}
+		},
+	},
+{{- end}}
+}
+`)
+	// creativeTemplate is the template used to generate vanilla/creative.go from the creative groups.
+	creativeTemplate = newSourceTemplate(`package {{.Package}}
+
+// CreativeGroup is a group of items in the creative inventory.
+type CreativeGroup struct {
+	Category int32
+	Name     string
+}
+
+// CreativeGroups holds all vanilla creative groups in the order they were sent, so that the index of a group
+// in the slice matches the group index of creative items.
+var CreativeGroups = []CreativeGroup{
+{{- range .Groups}}
+	{Category: {{.Category}}, Name: {{printf "%q" .Name}}},
+{{- end}}
+}
+`)
+)
+
+// newSourceTemplate parses a template used to generate a Go source file.
+func newSourceTemplate(text string) *template.Template {
+	return template.Must(template.New("").Funcs(template.FuncMap{"ident": identifier}).Parse(text))
+}
+
+// itemSource is the data passed to itemsTemplate.
+type itemSource struct {
+	Package string
+	Items   []namedVanillaItem
+}
+
+// namedVanillaItem is a VanillaItemEntry together with the name of the item.
+type namedVanillaItem struct {
+	Name string
+	VanillaItemEntry
+}
+
+// newItemSource creates the itemSource for the vanilla items passed, sorting them by their runtime ID.
+func newItemSource(items map[string]VanillaItemEntry) itemSource {
+	src := itemSource{Package: sourcePackage}
+	for _, name := range slices.Sorted(maps.Keys(items)) {
+		src.Items = append(src.Items, namedVanillaItem{Name: name, VanillaItemEntry: items[name]})
+	}
+	slices.SortStableFunc(src.Items, func(a, b namedVanillaItem) int {
+		return cmp.Compare(a.RuntimeID, b.RuntimeID)
+	})
+	return src
+}
+
+// biomeSource is the data passed to biomesTemplate.
+type biomeSource struct {
+	Package string
+	Biomes  []biomeEntry
+}
+
+// biomeEntry is a biome name together with its numerical ID.
+type biomeEntry struct {
+	Name string
+	ID   uint16
+}
+
+// newBiomeSource creates the biomeSource for the biome name to ID mapping passed, sorting the biomes by ID. It
+// panics if two biomes have the same ID or Go identifier, as BiomeNames would then hold a duplicate key.
+func newBiomeSource(biomes map[string]uint16) biomeSource {
+	src := biomeSource{Package: sourcePackage}
+	for _, name := range slices.Sorted(maps.Keys(biomes)) {
+		src.Biomes = append(src.Biomes, biomeEntry{Name: name, ID: biomes[name]})
+	}
+	slices.SortStableFunc(src.Biomes, func(a, b biomeEntry) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	for i := 1; i < len(src.Biomes); i++ {
+		if a, b := src.Biomes[i-1], src.Biomes[i]; a.ID == b.ID {
+			panic(fmt.Errorf("biomes %s and %s both have ID %d", a.Name, b.Name, a.ID))
+		}
+	}
+	checkIdentifiers(src.Biomes, func(b biomeEntry) string { return b.Name })
+	return src
+}
+
+// entitySource is the data passed to entitiesTemplate.
+type entitySource struct {
+	Package  string
+	Entities []string
+}
+
+// commandSource is the data passed to commandsTemplate.
+type commandSource struct {
+	Package  string
+	Commands []Command
+}
+
+// creativeSource is the data passed to creativeTemplate.
+type creativeSource struct {
+	Package string
+	Groups  []CreativeGroup
+}
+
+// identifier converts a namespaced identifier such as "minecraft:acacia_boat" into an exported Go identifier,
+// such as "AcaciaBoat". The "minecraft" namespace is omitted, while other namespaces are kept as a prefix.
+func identifier(name string) string {
+	name = strings.TrimPrefix(name, "minecraft:")
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// checkIdentifiers panics if two of the values passed would result in the same Go identifier.
+func checkIdentifiers[T any](values []T, name func(T) string) {
+	seen := make(map[string]string, len(values))
+	for _, v := range values {
+		n := name(v)
+		id := identifier(n)
+		if other, ok := seen[id]; ok {
+			panic(fmt.Errorf("%s and %s both result in Go identifier %s", other, n, id))
+		}
+		seen[id] = n
+	}
+}
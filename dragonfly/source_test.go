@@ -0,0 +1,110 @@
+package dragonfly
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"text/template"
+
+	"github.com/df-mc/datagen/write"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// captureFS is a write.FS that keeps the files written to it in memory, indexed by their path.
+type captureFS map[string][]byte
+
+func (c captureFS) WriteFile(path string, b []byte) error {
+	c[path] = b
+	return nil
+}
+
+// checkGolden compares the Go source generated using the template and data passed with the golden file at the path
+// passed, or updates the golden file if the -update flag is set.
+func checkGolden(t *testing.T, path string, tmpl *template.Template, v any) {
+	t.Helper()
+	fsys := captureFS{}
+	write.Go(fsys, "source.go", tmpl, v)
+	got := fsys["source.go"]
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s does not match golden file:\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"minecraft:acacia_boat", "AcaciaBoat"},
+		{"acacia_boat", "AcaciaBoat"},
+		{"minecraft:ender_dragon", "EnderDragon"},
+		{"minecraft:element_118", "Element118"},
+		{"minecraft:music_disc_5", "MusicDisc5"},
+		{"bamboo_jungle_hills", "BambooJungleHills"},
+		{"minecraft:agent.spawn-egg", "AgentSpawnEgg"},
+		{"custom:my_item", "CustomMyItem"},
+		{"minecraft:", ""},
+	}
+	for _, tt := range tests {
+		if got := identifier(tt.name); got != tt.want {
+			t.Errorf("identifier(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckIdentifiers(t *testing.T) {
+	checkIdentifiers([]string{"minecraft:cow", "minecraft:mooshroom", "custom:cow"}, func(s string) string { return s })
+
+	defer func() {
+		r := recover()
+		if want := "minecraft:snow_golem and snow.golem both result in Go identifier SnowGolem"; fmt.Sprint(r) != want {
+			t.Errorf("got panic %v, want %q", r, want)
+		}
+	}()
+	checkIdentifiers([]string{"minecraft:snow_golem", "snow.golem"}, func(s string) string { return s })
+}
+
+func TestNewBiomeSourceDuplicateID(t *testing.T) {
+	defer func() {
+		r := recover()
+		if want := "biomes desert and plains both have ID 1"; fmt.Sprint(r) != want {
+			t.Errorf("got panic %v, want %q", r, want)
+		}
+	}()
+	newBiomeSource(map[string]uint16{"plains": 1, "desert": 1, "ocean": 0})
+}
+
+func TestItemsSource(t *testing.T) {
+	checkGolden(t, filepath.Join("testdata", "items.go.golden"), itemsTemplate, newItemSource(map[string]VanillaItemEntry{
+		"minecraft:stick":         {RuntimeID: 320},
+		"minecraft:stone":         {RuntimeID: 1, Version: 2},
+		"minecraft:shield":        {RuntimeID: 355, ComponentBased: true, Version: 1},
+		"minecraft:bundle":        {RuntimeID: 400, Tags: Tags{Experiment: "bundle"}},
+		"minecraft:new_sword":     {RuntimeID: 320, RuntimeIDConflict: "minecraft:stick", Tags: Tags{Experiment: "swords"}},
+		"minecraft:element_1":     {RuntimeID: -12, Tags: Tags{Education: true}},
+		"minecraft:wooden_sword":  {RuntimeID: 308},
+		"minecraft:golden_carrot": {RuntimeID: 309},
+	}))
+}
+
+func TestBiomesSource(t *testing.T) {
+	checkGolden(t, filepath.Join("testdata", "biomes.go.golden"), biomesTemplate, newBiomeSource(map[string]uint16{
+		"plains":              1,
+		"ocean":               0,
+		"bamboo_jungle_hills": 49,
+		"the_end":             9,
+	}))
+}
@@ -4,6 +4,8 @@ go 1.24.1
 
 require (
 	github.com/df-mc/dragonfly v0.10.4
+	github.com/go-gl/mathgl v1.2.0
+	github.com/google/uuid v1.6.0
 	github.com/samber/lo v1.50.0
 	github.com/sandertv/gophertunnel v1.47.3
 	golang.org/x/oauth2 v0.30.0
@@ -13,10 +15,8 @@ require (
 	github.com/brentp/intintmap v0.0.0-20190211203843-30dc0ade9af9 // indirect
 	github.com/df-mc/goleveldb v1.1.9 // indirect
 	github.com/df-mc/worldupgrader v1.0.19 // indirect
-	github.com/go-gl/mathgl v1.2.0 // indirect
 	github.com/go-jose/go-jose/v4 v4.1.0 // indirect
 	github.com/golang/snappy v1.0.0 // indirect
-	github.com/google/uuid v1.6.0 // indirect
 	github.com/klauspost/compress v1.18.0 // indirect
 	github.com/muhammadmuzzammil1998/jsonc v1.0.0 // indirect
 	github.com/sandertv/go-raknet v1.14.3-0.20250305181847-6af3e95113d6 // indirect
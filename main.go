@@ -1,55 +1,102 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"flag"
+	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 
-	"github.com/df-mc/datagen/dragonfly"
-	"github.com/df-mc/datagen/pocketmine"
-	_ "github.com/df-mc/dragonfly/server/world"
-	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/df-mc/datagen/data"
+	"github.com/df-mc/datagen/datagen"
+	"github.com/df-mc/datagen/write"
 	"github.com/sandertv/gophertunnel/minecraft/auth"
-	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
 	"golang.org/x/oauth2"
 )
 
 func main() {
-	_ = os.RemoveAll("output")
+	dryRun := flag.Bool("dry-run", false, "compare the generated data with the existing output directory instead of writing it, exiting with status 1 if anything changed")
+	addrs := flag.String("addr", "127.0.0.1:19132", "comma separated list of addresses of servers to connect to, one session per address, ignored if -bds is set")
+	bdsDir := flag.String("bds", "", "directory of a BDS installation to configure, start and stop automatically")
+	education := flag.Bool("education", true, "enable education features in the world of the server started with -bds")
+	experiments := flag.String("experiments", "", "comma separated list of experiments to enable in the world of the server started with -bds, with semicolons separating the experiments of different sessions")
+	paletteDir := flag.String("palette", "", "directory of a BedrockData checkout to load canonical_block_states.nbt and block_state_meta_map.json from instead of the embedded bundles")
+	blockStates := flag.String("block-states", "", "path of canonical_block_states.nbt to use instead of the embedded bundle, overriding -palette")
+	metaMap := flag.String("meta-map", "", "path of block_state_meta_map.json to use instead of the embedded bundle, overriding -palette")
+	allowPacks := flag.Bool("allow-packs", false, "generate data even if the server has behaviour or resource packs active, in which case the data is not vanilla")
+	flag.Parse()
 
-	dialer := minecraft.Dialer{
-		TokenSource: tokenSource(),
+	if flag.NArg() > 0 {
+		runCommand(flag.Arg(0), flag.Args()[1:])
+		return
 	}
-	conn, err := dialer.Dial("raknet", "127.0.0.1:19132")
+	if *paletteDir != "" {
+		if *blockStates == "" {
+			*blockStates = filepath.Join(*paletteDir, "canonical_block_states.nbt")
+		}
+		if *metaMap == "" {
+			*metaMap = filepath.Join(*paletteDir, "block_state_meta_map.json")
+		}
+	}
+	var override *data.Palette
+	if *blockStates != "" || *metaMap != "" {
+		p, err := data.LoadPalette(*blockStates, *metaMap)
+		if err != nil {
+			panic(err)
+		}
+		override = p
+	}
+
+	tokens := tokenSource()
+	var src datagen.Source = datagen.Servers{Addrs: splitList(*addrs), TokenSource: tokens}
+	if *bdsDir != "" {
+		var sets [][]string
+		for _, set := range strings.Split(*experiments, ";") {
+			sets = append(sets, splitList(set))
+		}
+		src = datagen.BDS{Dir: *bdsDir, Education: *education, Experiments: sets, TokenSource: tokens, Log: os.Stdout}
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	res, err := datagen.Generate(ctx, src, datagen.Options{Palette: override, AllowPacks: *allowPacks})
+	stop()
+	saveToken(tokens)
 	if err != nil {
 		panic(err)
 	}
-	defer conn.Close()
 
-	if err := conn.DoSpawn(); err != nil {
+	for i, s := range res.Sessions {
+		if res.Bundles[i] != "" {
+			fmt.Printf("using palette bundle %s for server version %s\n", res.Bundles[i], s.GameVersion())
+		}
+	}
+	for _, w := range res.Warnings {
+		fmt.Println("warning:", w)
+	}
+
+	var fsys write.FS = write.DiskFS{}
+	var mem *write.MemFS
+	if *dryRun {
+		mem = write.NewMemFS()
+		fsys = mem
+	} else {
+		_ = os.RemoveAll("output")
+	}
+	if err := res.Write(fsys, "output"); err != nil {
 		panic(err)
 	}
-	dragonfly.HandleGameData(conn.GameData())
-	pocketmine.HandleGameData(conn.GameData())
 
-	for {
-		pk, err := conn.ReadPacket()
+	if mem != nil {
+		diff, err := mem.Diff("output")
 		if err != nil {
-			break
+			panic(err)
 		}
-
-		switch p := pk.(type) {
-		case *packet.AvailableActorIdentifiers:
-			pocketmine.HandleAvailableActorIdentifiers(p)
-		case *packet.BiomeDefinitionList:
-			pocketmine.HandleBiomeDefinitionList(p)
-		case *packet.CraftingData:
-			dragonfly.HandleCraftingData(p)
-			pocketmine.HandleCraftingData(p)
-		case *packet.CreativeContent:
-			dragonfly.HandleCreativeContent(p)
-			pocketmine.HandleCreativeContent(p)
+		diff.Print(os.Stdout)
+		if !diff.Empty() {
+			os.Exit(1)
 		}
 	}
 }
@@ -79,15 +126,24 @@ func tokenSource() oauth2.TokenSource {
 		check(err)
 		src = auth.RefreshTokenSource(token)
 	}
-	go func() {
-		c := make(chan os.Signal, 3)
-		signal.Notify(c, syscall.SIGTERM, syscall.SIGINT)
-		<-c
-
-		tok, _ := src.Token()
-		b, _ := json.Marshal(tok)
-		_ = os.WriteFile("token.tok", b, 0644)
-		os.Exit(0)
-	}()
 	return src
 }
+
+// splitList splits a comma separated list into its non-empty elements.
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// saveToken caches the current token of the token source passed in the token.tok file, so that it may be
+// re-used by tokenSource the next time the tool is run.
+func saveToken(src oauth2.TokenSource) {
+	tok, _ := src.Token()
+	b, _ := json.Marshal(tok)
+	_ = os.WriteFile("token.tok", b, 0644)
+}
@@ -1,8 +1,10 @@
 package pocketmine
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"slices"
 	"strings"
 
@@ -14,100 +16,152 @@ import (
 	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
 )
 
-func HandleGameData(gameData minecraft.GameData) {
-	requiredItemList := make(map[string]RequiredItemEntry)
+// Output holds all data generated for PocketMine. It is filled by the Handle methods and written to a write.FS
+// using Write.
+type Output struct {
+	RequiredItems     map[string]RequiredItemEntry
+	ItemIDs           map[string]int32
+	EntityIDs         map[string]int32
+	EntityIdentifiers []byte
+	Biomes            map[string]BiomeDefinition
+	BiomeIDs          map[string]int32
+	Recipes           map[string][]any
+	TrimPatterns      []TrimPatternData
+	TrimMaterials     []TrimMaterialData
+	Creative          CreativeItems
+	CreativeEducation CreativeItems
+
+	// Warnings holds the problems found while handling the data that did not prevent generating it.
+	Warnings []string
+
+	reg *data.Registry
+}
+
+// NewOutput returns a new, empty Output that resolves items and block states using the Registry passed.
+func NewOutput(reg *data.Registry) *Output {
+	return &Output{
+		reg:           reg,
+		RequiredItems: make(map[string]RequiredItemEntry),
+		ItemIDs:       make(map[string]int32),
+		EntityIDs:     make(map[string]int32),
+		Biomes:        make(map[string]BiomeDefinition),
+		BiomeIDs:      make(map[string]int32),
+		Recipes:       make(map[string][]any),
+	}
+}
+
+// Write writes all data held by the Output to the FS passed.
+func (o *Output) Write(fsys write.FS) {
+	write.JSON(fsys, "required_item_list.json", o.RequiredItems)
+	writeIDMap(fsys, "item_id_map.json", o.ItemIDs)
+	writeIDMap(fsys, "entity_id_map.json", o.EntityIDs)
+	if o.EntityIdentifiers != nil {
+		write.Raw(fsys, "entity_identifiers.nbt", o.EntityIdentifiers)
+	}
+	write.JSON(fsys, "biome_definitions.json", o.Biomes)
+	writeIDMap(fsys, "biome_id_map.json", o.BiomeIDs)
+	for k, v := range o.Recipes {
+		write.JSON(fsys, fmt.Sprintf("recipes/%s.json", k), v)
+	}
+	write.JSON(fsys, "trim_patterns.json", o.TrimPatterns)
+	write.JSON(fsys, "trim_materials.json", o.TrimMaterials)
+	write.JSON(fsys, "creativeitems.json", o.Creative)
+	write.JSON(fsys, "creativeitems_education.json", o.CreativeEducation)
+}
+
+func (o *Output) HandleGameData(gameData minecraft.GameData) {
 	for _, item := range gameData.Items {
-		data.ItemNameToNetworkID[item.Name] = int32(item.RuntimeID)
-		data.ItemNetworkIDToName[int32(item.RuntimeID)] = item.Name
-		requiredItemList[item.Name] = RequiredItemEntry{
+		o.RequiredItems[item.Name] = RequiredItemEntry{
 			RuntimeID:      item.RuntimeID,
 			ComponentBased: item.ComponentBased,
 		}
+		// The item ID map holds the runtime IDs of vanilla items as sent by the server, not their legacy IDs.
+		if strings.HasPrefix(item.Name, "minecraft:") {
+			o.ItemIDs[item.Name] = int32(item.RuntimeID)
+		}
 	}
-	write.JSON("output/pocketmine/required_item_list.json", requiredItemList)
 }
 
-func HandleAvailableActorIdentifiers(pk *packet.AvailableActorIdentifiers) {
+func (o *Output) HandleAvailableActorIdentifiers(pk *packet.AvailableActorIdentifiers) {
 	var identifiers AvailableActorIdentifiers
 	err := nbt.Unmarshal(pk.SerialisedEntityIdentifiers, &identifiers)
 	if err != nil {
 		panic(fmt.Errorf("failed to unmarshal entity identifiers: %w", err))
 	}
-	list := identifiers.IDList
-	slices.SortFunc(list, func(a, b ActorIdentifier) int {
-		return int(a.RuntimeID - b.RuntimeID)
-	})
-	var lines []string
-	for _, id := range list {
-		lines = append(lines, fmt.Sprintf("\t\"%s\": %d", id.ID, id.RuntimeID))
+	for _, id := range identifiers.IDList {
+		o.EntityIDs[id.ID] = id.RuntimeID
 	}
-	b := []byte(fmt.Sprintf("{\n%s\n}", strings.Join(lines, ",\n")))
-	write.Raw("output/pocketmine/entity_id_map.json", b)
-	write.Raw("output/pocketmine/entity_identifiers.nbt", pk.SerialisedEntityIdentifiers)
+	o.EntityIdentifiers = pk.SerialisedEntityIdentifiers
 }
 
-func HandleBiomeDefinitionList(pk *packet.BiomeDefinitionList) {
-	biomes := make(map[string]BiomeDefinition)
+func (o *Output) HandleBiomeDefinitionList(pk *packet.BiomeDefinitionList) {
 	list := pk.StringList
 	for _, definition := range pk.BiomeDefinitions {
 		name := list[definition.NameIndex]
-		biomes[name] = newBiomeDefinition(definition, list)
+		o.Biomes[name] = newBiomeDefinition(definition, list)
+		if id, ok := definition.BiomeID.Value(); ok {
+			o.BiomeIDs[name] = int32(id)
+		}
 	}
-	write.JSON("output/pocketmine/biome_definitions.json", biomes)
 }
 
-func HandleCraftingData(pk *packet.CraftingData) {
-	recipes := make(map[string][]any)
+func (o *Output) HandleCraftingData(pk *packet.CraftingData) {
+	reg, recipes := o.reg, o.Recipes
 	for _, recipe := range pk.Recipes {
 		var key string
 		var value any
 		switch r := recipe.(type) {
 		case *protocol.ShapelessRecipe:
 			key = "shapeless_crafting"
-			value = shapelessRecipeData(r)
+			value = shapelessRecipeData(reg, r)
 		case *protocol.ShapedRecipe:
 			key = "shaped_crafting"
 			if !r.AssumeSymmetry {
 				key += "_asymmetric"
 			}
-			value = shapedRecipeData(r)
+			value = shapedRecipeData(reg, r)
 		case *protocol.FurnaceRecipe:
 			key = "smelting"
-			value = furnaceRecipeData(r)
+			value = furnaceRecipeData(reg, r)
 		case *protocol.FurnaceDataRecipe:
 			key = "smelting"
-			value = furnaceRecipeData(&r.FurnaceRecipe)
+			value = furnaceRecipeData(reg, &r.FurnaceRecipe)
 		case *protocol.MultiRecipe:
 			key = "special_hardcoded"
 			value = r.UUID.String()
 		case *protocol.ShulkerBoxRecipe:
 			key = "shapeless_shulker_box"
-			value = shapelessRecipeData(&r.ShapelessRecipe)
+			value = shapelessRecipeData(reg, &r.ShapelessRecipe)
 		case *protocol.ShapelessChemistryRecipe:
 			key = "shapeless_chemistry"
-			value = shapelessRecipeData(&r.ShapelessRecipe)
+			value = shapelessRecipeData(reg, &r.ShapelessRecipe)
 		case *protocol.ShapedChemistryRecipe:
 			key = "shaped_chemistry"
 			if !r.AssumeSymmetry {
 				key += "_asymmetric"
 			}
-			value = shapedRecipeData(&r.ShapedRecipe)
+			value = shapedRecipeData(reg, &r.ShapedRecipe)
 		case *protocol.SmithingTransformRecipe:
 			key = "smithing"
-			value = smithingTransformRecipeData(r)
+			value = smithingTransformRecipeData(reg, r)
 		case *protocol.SmithingTrimRecipe:
 			key = "smithing_trim"
-			value = smithingTrimRecipeData(r)
+			value = smithingTrimRecipeData(reg, r)
 		default:
 			panic(fmt.Errorf("unknown recipe type %T", r))
 		}
+		key = educationKey(reg, key, value)
 		recipes[key] = append(recipes[key], value)
 	}
 	for _, r := range pk.PotionRecipes {
-		recipes["potion_type"] = append(recipes["potion_type"], potionTypeRecipeData(r))
+		value := potionTypeRecipeData(reg, r)
+		key := educationKey(reg, "potion_type", value)
+		recipes[key] = append(recipes[key], value)
 	}
 	for _, r := range pk.PotionContainerChangeRecipes {
-		recipes["potion_container_change"] = append(recipes["potion_container_change"], potionContainerChangeRecipeData(r))
+		value := potionContainerChangeRecipeData(reg, r)
+		key := educationKey(reg, "potion_container_change", value)
+		recipes[key] = append(recipes[key], value)
 	}
 
 	type keyValue struct {
@@ -133,31 +187,59 @@ func HandleCraftingData(pk *packet.CraftingData) {
 		})
 		for key, count := range seen {
 			if count > 1 {
-				fmt.Printf("warning: %s recipe %s was seen %d times\n", name, key, count)
+				o.Warnings = append(o.Warnings, fmt.Sprintf("%s recipe %s was seen %d times", name, key, count))
 			}
 		}
 	}
-	for k, v := range recipes {
-		write.JSON(fmt.Sprintf("output/pocketmine/recipes/%s.json", k), v)
+}
+
+func (o *Output) HandleTrimData(pk *packet.TrimData) {
+	patterns := make([]TrimPatternData, 0, len(pk.Patterns))
+	for _, p := range pk.Patterns {
+		patterns = append(patterns, TrimPatternData{ItemName: p.ItemName, PatternID: p.PatternID})
+	}
+	materials := make([]TrimMaterialData, 0, len(pk.Materials))
+	for _, m := range pk.Materials {
+		materials = append(materials, TrimMaterialData{MaterialID: m.MaterialID, Colour: m.Colour, ItemName: m.ItemName})
 	}
+	o.TrimPatterns, o.TrimMaterials = patterns, materials
 }
 
-func HandleCreativeContent(pk *packet.CreativeContent) {
+func (o *Output) HandleCreativeContent(pk *packet.CreativeContent) {
+	reg := o.reg
 	var content CreativeItems
 	for _, group := range pk.Groups {
 		content.Groups = append(content.Groups, CreativeGroup{
 			CategoryID:   group.Category,
 			CategoryName: group.Name,
-			Icon:         itemStackData(group.Icon),
+			Icon:         itemStackData(reg, group.Icon),
 		})
 	}
+	// Education items are written to a separate file with the same groups, so that servers without education
+	// features can leave them out.
+	education := CreativeItems{Groups: content.Groups}
 	for _, item := range pk.Items {
-		content.Items = append(content.Items, CreativeItem{
+		ci := CreativeItem{
 			GroupID: item.GroupIndex,
-			Item:    itemStackData(item.Item),
-		})
+			Item:    itemStackData(reg, item.Item),
+		}
+		if reg.IsEducationItem(ci.Item.Name) {
+			education.Items = append(education.Items, ci)
+			continue
+		}
+		content.Items = append(content.Items, ci)
+	}
+	o.Creative, o.CreativeEducation = content, education
+}
+
+// educationKey returns the key of the recipe file that a recipe with the key and value passed should be written
+// to. Recipes only available with education features enabled are written to a separate file with an
+// "_education" suffix, except for chemistry recipes, which already have their own files.
+func educationKey(reg *data.Registry, key string, value any) string {
+	if strings.Contains(key, "chemistry") || !educationRecipe(reg, value) {
+		return key
 	}
-	write.JSON("output/pocketmine/creativeitems.json", content)
+	return key + "_education"
 }
 
 func mapSlice[A, B any](s []A, f func(A) B) []B {
@@ -167,3 +249,23 @@ func mapSlice[A, B any](s []A, f func(A) B) []B {
 	}
 	return r
 }
+
+// withRegistry returns a function calling f with the Registry passed, so that it may be used with mapSlice.
+func withRegistry[A, B any](reg *data.Registry, f func(*data.Registry, A) B) func(A) B {
+	return func(a A) B {
+		return f(reg, a)
+	}
+}
+
+// writeIDMap writes the numeric IDs passed to a JSON object at the path passed in fsys, sorted by their ID.
+func writeIDMap(fsys write.FS, path string, ids map[string]int32) {
+	names := slices.SortedFunc(maps.Keys(ids), func(a, b string) int {
+		return cmp.Or(cmp.Compare(ids[a], ids[b]), strings.Compare(a, b))
+	})
+	var lines []string
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("\t\"%s\": %d", name, ids[name]))
+	}
+	b := []byte(fmt.Sprintf("{\n%s\n}", strings.Join(lines, ",\n")))
+	write.Raw(fsys, path, b)
+}
@@ -0,0 +1,59 @@
+package pocketmine
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/df-mc/datagen/write"
+	"github.com/google/uuid"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+func TestOutputWrite(t *testing.T) {
+	o := NewOutput(testRegistry())
+	o.HandleTrimData(&packet.TrimData{Patterns: []protocol.TrimPattern{{ItemName: "minecraft:coast_armor_trim_smithing_template", PatternID: "coast"}}})
+	id := uuid.New()
+	o.HandleCraftingData(&packet.CraftingData{Recipes: []protocol.Recipe{&protocol.MultiRecipe{UUID: id}, &protocol.MultiRecipe{UUID: id}}})
+	if len(o.Warnings) != 1 {
+		t.Errorf("expected a warning for the duplicate recipe, got %q", o.Warnings)
+	}
+
+	dir := t.TempDir()
+	mem := write.NewMemFS()
+	o.Write(write.Sub(mem, dir))
+	diff, err := mem.Diff(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"trim_patterns.json", "recipes/special_hardcoded.json", "creativeitems.json"} {
+		if !slices.Contains(diff.Created, filepath.Join(dir, path)) {
+			t.Errorf("%s not written: %v", path, diff.Created)
+		}
+	}
+}
+
+func TestEducationKey(t *testing.T) {
+	reg := testRegistry().WithEducationItems([]string{"minecraft:derived_compound"})
+	tests := []struct {
+		key   string
+		value any
+		want  string
+	}{
+		{"shapeless_crafting", ShapelessRecipeData{Block: "crafting_table", Input: []RecipeIngredientData{{Name: "minecraft:stone"}}, Output: []ItemStackData{{Name: "minecraft:stick"}}}, "shapeless_crafting"},
+		{"shapeless_crafting", ShapelessRecipeData{Block: "lab_table", Output: []ItemStackData{{Name: "minecraft:stick"}}}, "shapeless_crafting_education"},
+		{"shaped_crafting", ShapedRecipeData{Block: "crafting_table", Input: []RecipeIngredientData{{Name: "minecraft:element_1"}}, Output: []ItemStackData{{Name: "minecraft:stick"}}}, "shaped_crafting_education"},
+		{"shaped_crafting", ShapedRecipeData{Block: "crafting_table", Input: []RecipeIngredientData{{Tag: "minecraft:edu_item"}}}, "shaped_crafting_education"},
+		{"shaped_crafting", ShapedRecipeData{Block: "crafting_table", Output: []ItemStackData{{Name: "minecraft:derived_compound"}}}, "shaped_crafting_education"},
+		// Chemistry recipes already have their own files.
+		{"shapeless_chemistry", ShapelessRecipeData{Block: "compound_creator", Output: []ItemStackData{{Name: "minecraft:compound"}}}, "shapeless_chemistry"},
+		{"potion_container_change", PotionContainerChangeRecipeData{InputItemName: "minecraft:potion", OutputItemName: "minecraft:medicine"}, "potion_container_change_education"},
+		{"special_hardcoded", "00000000-0000-0000-0000-000000000000", "special_hardcoded"},
+	}
+	for _, tt := range tests {
+		if got := educationKey(reg, tt.key, tt.value); got != tt.want {
+			t.Errorf("educationKey(%s, %+v) = %s, want %s", tt.key, tt.value, got, tt.want)
+		}
+	}
+}
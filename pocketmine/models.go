@@ -5,10 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"slices"
 	"strings"
 
 	"github.com/df-mc/datagen/data"
-	"github.com/df-mc/dragonfly/server/world/chunk"
 	"github.com/samber/lo"
 	"github.com/sandertv/gophertunnel/minecraft/nbt"
 	"github.com/sandertv/gophertunnel/minecraft/protocol"
@@ -58,9 +58,9 @@ type ItemStackData struct {
 	NBT         []byte `json:"nbt,omitempty"`
 }
 
-func itemStackData(s protocol.ItemStack) ItemStackData {
+func itemStackData(reg *data.Registry, s protocol.ItemStack) ItemStackData {
 	stack := ItemStackData{
-		Name: data.ItemNetworkIDToName[s.NetworkID],
+		Name: itemName(reg, s.NetworkID),
 		Meta: int16(s.MetadataValue),
 	}
 	if stack.Meta == math.MaxInt16 {
@@ -77,7 +77,7 @@ func itemStackData(s protocol.ItemStack) ItemStackData {
 		if stack.Meta != 0 {
 			panic(fmt.Errorf("block item %s has non-zero metadata %d", stack.Name, stack.Meta))
 		}
-		_, props, ok := chunk.RuntimeIDToState(uint32(s.BlockRuntimeID))
+		_, props, ok := reg.BlockState(uint32(s.BlockRuntimeID))
 		if ok {
 			b, err := nbt.MarshalEncoding(props, nbt.LittleEndian)
 			if err != nil {
@@ -101,27 +101,14 @@ type RecipeIngredientData struct {
 	Tag              string `json:"tag,omitempty"`
 }
 
-func recipeIngredientData(c protocol.ItemDescriptorCount) RecipeIngredientData {
+func recipeIngredientData(reg *data.Registry, c protocol.ItemDescriptorCount) RecipeIngredientData {
 	var ingredient RecipeIngredientData
 	switch d := c.Descriptor.(type) {
 	case *protocol.InvalidItemDescriptor:
 		panic("invalid item descriptor")
 	case *protocol.DefaultItemDescriptor:
-		ingredient.Name = data.ItemNetworkIDToName[int32(d.NetworkID)]
-		if d.MetadataValue == 32767 {
-			_, props, ok := chunk.RuntimeIDToState(uint32(d.MetadataValue))
-			if ok {
-				b, err := nbt.MarshalEncoding(props, nbt.LittleEndian)
-				if err != nil {
-					panic(fmt.Errorf("failed to marshal block properties for item %s: %w", ingredient.Name, err))
-				}
-				ingredient.BlockStates = b
-			} else {
-				ingredient.Meta = d.MetadataValue
-			}
-		} else {
-			ingredient.Meta = d.MetadataValue
-		}
+		ingredient.Name = itemName(reg, int32(d.NetworkID))
+		ingredient.resolveMeta(reg, d.MetadataValue)
 	case *protocol.MoLangItemDescriptor:
 		ingredient.MolangExpression = d.Expression
 		ingredient.MolangVersion = d.Version
@@ -129,20 +116,7 @@ func recipeIngredientData(c protocol.ItemDescriptorCount) RecipeIngredientData {
 		ingredient.Tag = d.Tag
 	case *protocol.DeferredItemDescriptor:
 		ingredient.Name = d.Name
-		if d.MetadataValue == 32767 {
-			_, props, ok := chunk.RuntimeIDToState(uint32(d.MetadataValue))
-			if ok {
-				b, err := nbt.MarshalEncoding(props, nbt.LittleEndian)
-				if err != nil {
-					panic(fmt.Errorf("failed to marshal block properties for item %s: %w", ingredient.Name, err))
-				}
-				ingredient.BlockStates = b
-			} else {
-				ingredient.Meta = d.MetadataValue
-			}
-		} else {
-			ingredient.Meta = d.MetadataValue
-		}
+		ingredient.resolveMeta(reg, d.MetadataValue)
 	case *protocol.ComplexAliasItemDescriptor:
 		ingredient.Name = d.Name
 	}
@@ -152,22 +126,43 @@ func recipeIngredientData(c protocol.ItemDescriptorCount) RecipeIngredientData {
 	return ingredient
 }
 
+// resolveMeta sets the meta value of the ingredient, or the block states it places if the item places a block with
+// the meta value passed, as the meta value of block items is not meaningful. Wildcard meta values, which match
+// any meta value of the item, are kept as they are.
+func (d *RecipeIngredientData) resolveMeta(reg *data.Registry, meta int16) {
+	if meta == math.MaxInt16 {
+		d.Meta = meta
+		return
+	}
+	state, ok := reg.ItemMetaBlockState(d.Name, int32(meta))
+	if !ok {
+		d.Meta = meta
+		return
+	}
+	properties, _ := state["states"].(map[string]any)
+	b, err := nbt.MarshalEncoding(properties, nbt.LittleEndian)
+	if err != nil {
+		panic(fmt.Errorf("failed to marshal block properties for item %s: %w", d.Name, err))
+	}
+	d.BlockStates = b
+}
+
 type FurnaceRecipeData struct {
 	Block  string               `json:"block"`
 	Input  RecipeIngredientData `json:"input"`
 	Output ItemStackData        `json:"output"`
 }
 
-func furnaceRecipeData(r *protocol.FurnaceRecipe) FurnaceRecipeData {
+func furnaceRecipeData(reg *data.Registry, r *protocol.FurnaceRecipe) FurnaceRecipeData {
 	return FurnaceRecipeData{
-		Input: recipeIngredientData(protocol.ItemDescriptorCount{
+		Input: recipeIngredientData(reg, protocol.ItemDescriptorCount{
 			Count: 1,
 			Descriptor: &protocol.DefaultItemDescriptor{
 				NetworkID:     int16(r.InputType.NetworkID),
 				MetadataValue: int16(r.InputType.MetadataValue),
 			},
 		}),
-		Output: itemStackData(r.Output),
+		Output: itemStackData(reg, r.Output),
 		Block:  r.Block,
 	}
 }
@@ -178,23 +173,23 @@ type PotionTypeRecipeData struct {
 	Output     ItemStackData        `json:"output"`
 }
 
-func potionTypeRecipeData(r protocol.PotionRecipe) PotionTypeRecipeData {
+func potionTypeRecipeData(reg *data.Registry, r protocol.PotionRecipe) PotionTypeRecipeData {
 	return PotionTypeRecipeData{
-		Input: recipeIngredientData(protocol.ItemDescriptorCount{
+		Input: recipeIngredientData(reg, protocol.ItemDescriptorCount{
 			Count: 1,
 			Descriptor: &protocol.DefaultItemDescriptor{
 				NetworkID:     int16(r.InputPotionID),
 				MetadataValue: int16(r.InputPotionMetadata),
 			},
 		}),
-		Ingredient: recipeIngredientData(protocol.ItemDescriptorCount{
+		Ingredient: recipeIngredientData(reg, protocol.ItemDescriptorCount{
 			Count: 1,
 			Descriptor: &protocol.DefaultItemDescriptor{
 				NetworkID:     int16(r.ReagentItemID),
 				MetadataValue: int16(r.ReagentItemMetadata),
 			},
 		}),
-		Output: itemStackData(protocol.ItemStack{
+		Output: itemStackData(reg, protocol.ItemStack{
 			ItemType: protocol.ItemType{
 				NetworkID:     r.OutputPotionID,
 				MetadataValue: uint32(r.OutputPotionMetadata),
@@ -209,16 +204,16 @@ type PotionContainerChangeRecipeData struct {
 	OutputItemName string               `json:"output_item_name"`
 }
 
-func potionContainerChangeRecipeData(r protocol.PotionContainerChangeRecipe) PotionContainerChangeRecipeData {
+func potionContainerChangeRecipeData(reg *data.Registry, r protocol.PotionContainerChangeRecipe) PotionContainerChangeRecipeData {
 	return PotionContainerChangeRecipeData{
-		InputItemName: data.ItemNetworkIDToName[r.InputItemID],
-		Ingredient: recipeIngredientData(protocol.ItemDescriptorCount{
+		InputItemName: itemName(reg, r.InputItemID),
+		Ingredient: recipeIngredientData(reg, protocol.ItemDescriptorCount{
 			Count: 1,
 			Descriptor: &protocol.DefaultItemDescriptor{
 				NetworkID: int16(r.ReagentItemID),
 			},
 		}),
-		OutputItemName: data.ItemNetworkIDToName[r.OutputItemID],
+		OutputItemName: itemName(reg, r.OutputItemID),
 	}
 }
 
@@ -231,7 +226,7 @@ type ShapedRecipeData struct {
 	UnlockingIngredients []RecipeIngredientData `json:"unlockingIngredients,omitempty"`
 }
 
-func shapedRecipeData(r *protocol.ShapedRecipe) ShapedRecipeData {
+func shapedRecipeData(reg *data.Registry, r *protocol.ShapedRecipe) ShapedRecipeData {
 	var inputs []protocol.ItemDescriptorCount
 	shape := make([][]string, r.Width)
 	keys := make(map[string]string)
@@ -245,7 +240,7 @@ func shapedRecipeData(r *protocol.ShapedRecipe) ShapedRecipeData {
 				shape[x][y] = " "
 				continue
 			}
-			hash, _ := json.Marshal(recipeIngredientData(ingredient))
+			hash, _ := json.Marshal(recipeIngredientData(reg, ingredient))
 			if k, ok := keys[string(hash)]; ok {
 				shape[x][y] = k
 				continue
@@ -262,11 +257,11 @@ func shapedRecipeData(r *protocol.ShapedRecipe) ShapedRecipeData {
 		Shape: mapSlice(shape, func(s []string) string {
 			return strings.Join(s, "")
 		}),
-		Input:                mapSlice(inputs, recipeIngredientData),
-		Output:               mapSlice(r.Output, itemStackData),
+		Input:                mapSlice(inputs, withRegistry(reg, recipeIngredientData)),
+		Output:               mapSlice(r.Output, withRegistry(reg, itemStackData)),
 		Block:                r.Block,
 		Priority:             r.Priority,
-		UnlockingIngredients: mapSlice(r.UnlockRequirement.Ingredients, recipeIngredientData),
+		UnlockingIngredients: mapSlice(r.UnlockRequirement.Ingredients, withRegistry(reg, recipeIngredientData)),
 	}
 }
 
@@ -278,13 +273,13 @@ type ShapelessRecipeData struct {
 	UnlockingIngredients []RecipeIngredientData `json:"unlockingIngredients,omitempty"`
 }
 
-func shapelessRecipeData(r *protocol.ShapelessRecipe) ShapelessRecipeData {
+func shapelessRecipeData(reg *data.Registry, r *protocol.ShapelessRecipe) ShapelessRecipeData {
 	return ShapelessRecipeData{
-		Input:                mapSlice(r.Input, recipeIngredientData),
-		Output:               mapSlice(r.Output, itemStackData),
+		Input:                mapSlice(r.Input, withRegistry(reg, recipeIngredientData)),
+		Output:               mapSlice(r.Output, withRegistry(reg, itemStackData)),
 		Block:                r.Block,
 		Priority:             r.Priority,
-		UnlockingIngredients: mapSlice(r.UnlockRequirement.Ingredients, recipeIngredientData),
+		UnlockingIngredients: mapSlice(r.UnlockRequirement.Ingredients, withRegistry(reg, recipeIngredientData)),
 	}
 }
 
@@ -296,12 +291,12 @@ type SmithingTransformRecipeData struct {
 	Template RecipeIngredientData `json:"template"`
 }
 
-func smithingTransformRecipeData(r *protocol.SmithingTransformRecipe) SmithingTransformRecipeData {
+func smithingTransformRecipeData(reg *data.Registry, r *protocol.SmithingTransformRecipe) SmithingTransformRecipeData {
 	return SmithingTransformRecipeData{
-		Template: recipeIngredientData(r.Template),
-		Input:    recipeIngredientData(r.Base),
-		Addition: recipeIngredientData(r.Addition),
-		Output:   itemStackData(r.Result),
+		Template: recipeIngredientData(reg, r.Template),
+		Input:    recipeIngredientData(reg, r.Base),
+		Addition: recipeIngredientData(reg, r.Addition),
+		Output:   itemStackData(reg, r.Result),
 		Block:    r.Block,
 	}
 }
@@ -313,15 +308,60 @@ type SmithingTrimRecipeData struct {
 	Template RecipeIngredientData `json:"template"`
 }
 
-func smithingTrimRecipeData(r *protocol.SmithingTrimRecipe) SmithingTrimRecipeData {
+func smithingTrimRecipeData(reg *data.Registry, r *protocol.SmithingTrimRecipe) SmithingTrimRecipeData {
 	return SmithingTrimRecipeData{
-		Template: recipeIngredientData(r.Template),
-		Input:    recipeIngredientData(r.Base),
-		Addition: recipeIngredientData(r.Addition),
+		Template: recipeIngredientData(reg, r.Template),
+		Input:    recipeIngredientData(reg, r.Base),
+		Addition: recipeIngredientData(reg, r.Addition),
 		Block:    r.Block,
 	}
 }
 
+// itemName returns the name of the item with the network ID passed, or an empty string if the network ID is 0,
+// which is used for empty item stacks. It panics if no item with the network ID exists.
+func itemName(reg *data.Registry, networkID int32) string {
+	if networkID == 0 {
+		return ""
+	}
+	name, ok := reg.ItemName(networkID)
+	if !ok {
+		panic(fmt.Errorf("no item with network ID %d", networkID))
+	}
+	return name
+}
+
+// educationRecipe checks if the recipe data passed contains items only available with education features
+// enabled according to the Registry passed, or if it is crafted in a block only available with education features
+// enabled.
+func educationRecipe(reg *data.Registry, value any) bool {
+	var (
+		block  string
+		input  []RecipeIngredientData
+		output []ItemStackData
+	)
+	switch r := value.(type) {
+	case ShapedRecipeData:
+		block, input, output = r.Block, r.Input, r.Output
+	case ShapelessRecipeData:
+		block, input, output = r.Block, r.Input, r.Output
+	case FurnaceRecipeData:
+		block, input, output = r.Block, []RecipeIngredientData{r.Input}, []ItemStackData{r.Output}
+	case SmithingTransformRecipeData:
+		block, input, output = r.Block, []RecipeIngredientData{r.Template, r.Input, r.Addition}, []ItemStackData{r.Output}
+	case SmithingTrimRecipeData:
+		block, input = r.Block, []RecipeIngredientData{r.Template, r.Input, r.Addition}
+	case PotionTypeRecipeData:
+		input, output = []RecipeIngredientData{r.Input, r.Ingredient}, []ItemStackData{r.Output}
+	case PotionContainerChangeRecipeData:
+		input, output = []RecipeIngredientData{r.Ingredient}, []ItemStackData{{Name: r.InputItemName}, {Name: r.OutputItemName}}
+	}
+	return data.IsEducationBlock(block) || slices.ContainsFunc(input, func(i RecipeIngredientData) bool {
+		return reg.IsEducationItem(i.Name) || data.IsEducationTag(i.Tag)
+	}) || slices.ContainsFunc(output, func(s ItemStackData) bool {
+		return reg.IsEducationItem(s.Name)
+	})
+}
+
 type Colour struct {
 	A uint8 `json:"a"`
 	R uint8 `json:"r"`
@@ -382,3 +422,14 @@ func newBiomeDefinition(definition protocol.BiomeDefinition, list []string) Biom
 		Tags:             tags,
 	}
 }
+
+type TrimPatternData struct {
+	ItemName  string `json:"item_name"`
+	PatternID string `json:"pattern_id"`
+}
+
+type TrimMaterialData struct {
+	MaterialID string `json:"material_id"`
+	Colour     string `json:"colour"`
+	ItemName   string `json:"item_name"`
+}
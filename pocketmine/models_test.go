@@ -0,0 +1,68 @@
+package pocketmine
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/df-mc/datagen/data"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// testRegistry returns a Registry with the embedded palette and a small set of items.
+func testRegistry() *data.Registry {
+	return data.NewRegistry(minecraft.GameData{Items: []protocol.ItemEntry{
+		{Name: "minecraft:oak_log", RuntimeID: 17},
+		{Name: "minecraft:stone", RuntimeID: 1},
+		{Name: "minecraft:stick", RuntimeID: 320},
+	}}, data.DefaultPalette())
+}
+
+func TestRecipeIngredientData(t *testing.T) {
+	reg := testRegistry()
+	tests := []struct {
+		name       string
+		ingredient protocol.ItemDescriptorCount
+	}{
+		{"wildcard", protocol.ItemDescriptorCount{Count: 1, Descriptor: &protocol.DefaultItemDescriptor{NetworkID: 17, MetadataValue: 32767}}},
+		{"wildcard_deferred", protocol.ItemDescriptorCount{Count: 1, Descriptor: &protocol.DeferredItemDescriptor{Name: "minecraft:oak_log", MetadataValue: 32767}}},
+		{"block_item", protocol.ItemDescriptorCount{Count: 2, Descriptor: &protocol.DefaultItemDescriptor{NetworkID: 17, MetadataValue: 1}}},
+		{"block_item_deferred", protocol.ItemDescriptorCount{Count: 1, Descriptor: &protocol.DeferredItemDescriptor{Name: "minecraft:stone"}}},
+		{"item_meta", protocol.ItemDescriptorCount{Count: 1, Descriptor: &protocol.DefaultItemDescriptor{NetworkID: 320, MetadataValue: 3}}},
+		{"tag", protocol.ItemDescriptorCount{Count: 4, Descriptor: &protocol.ItemTagItemDescriptor{Tag: "minecraft:planks"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.MarshalIndent(recipeIngredientData(reg, tt.ingredient), "", "    ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, filepath.Join("testdata", "ingredient_"+tt.name+".json"), got)
+		})
+	}
+}
+
+// checkGolden compares the data passed with the golden file at the path passed, overwriting the file instead if
+// the -update flag is set.
+func checkGolden(t *testing.T, path string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(bytes.TrimSpace(got), bytes.TrimSpace(want)) {
+		t.Errorf("%s does not match golden file:\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
@@ -0,0 +1,174 @@
+package session
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// Session holds the game data and the packets that data is generated from, as received during a single
+// connection to a server.
+type Session struct {
+	// GameData is the game data sent by the server in the StartGame packet.
+	GameData minecraft.GameData
+	// Packets holds the first packet received for each packet ID that data is generated from.
+	Packets map[uint32]packet.Packet
+	// Repeated holds all packets received, in order, for packet IDs that the server sends more than once, such as
+	// one SyncActorProperty packet per entity type.
+	Repeated map[uint32][]packet.Packet
+}
+
+// New creates a new Session for the game data passed with no packets yet.
+func New(gameData minecraft.GameData) *Session {
+	return &Session{GameData: gameData, Packets: make(map[uint32]packet.Packet), Repeated: make(map[uint32][]packet.Packet)}
+}
+
+// Add adds a packet to the Session, replacing any packet previously added with the same ID.
+func (s *Session) Add(pk packet.Packet) {
+	s.Packets[pk.ID()] = pk
+}
+
+// AddRepeated adds a packet to the Session after all packets previously added with the same ID using
+// AddRepeated.
+func (s *Session) AddRepeated(pk packet.Packet) {
+	s.Repeated[pk.ID()] = append(s.Repeated[pk.ID()], pk)
+}
+
+// Packet returns the packet of type T added to the Session. If no such packet was added, false is returned.
+func Packet[T packet.Packet](s *Session) (T, bool) {
+	var zero T
+	pk, ok := s.Packets[zero.ID()]
+	if !ok {
+		return zero, false
+	}
+	v, ok := pk.(T)
+	return v, ok
+}
+
+// Experiments returns the names of all experiments that were enabled on the server during the Session, sorted
+// alphabetically.
+func (s *Session) Experiments() []string {
+	var names []string
+	for _, experiment := range s.GameData.Experiments {
+		if experiment.Enabled {
+			names = append(names, experiment.Name)
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// GameVersion returns the version of the game that the server was running during the Session, or an empty string
+// if the StartGame packet was not captured.
+func (s *Session) GameVersion() string {
+	if pk, ok := Packet[*packet.StartGame](s); ok {
+		return pk.GameVersion
+	}
+	return ""
+}
+
+// Pack is a single behaviour or resource pack that was active during a Session.
+type Pack struct {
+	UUID        string `json:"uuid"`
+	Version     string `json:"version"`
+	Type        string `json:"type"`
+	SubPackName string `json:"sub_pack_name,omitempty"`
+	Size        uint64 `json:"size,omitempty"`
+	HasScripts  bool   `json:"has_scripts,omitempty"`
+	Addon       bool   `json:"addon,omitempty"`
+}
+
+// Packs returns the behaviour and resource packs that were active during the Session, in the order of the pack
+// stack, with behaviour packs first. Packs that were announced but not part of the stack are added at the end.
+func (s *Session) Packs() []Pack {
+	info, _ := Packet[*packet.ResourcePacksInfo](s)
+	stack, _ := Packet[*packet.ResourcePackStack](s)
+	announced := make(map[string]protocol.TexturePackInfo)
+	if info != nil {
+		for _, p := range info.TexturePacks {
+			announced[p.UUID.String()] = p
+		}
+	}
+	packs := []Pack{}
+	add := func(uuid, version, subPack, kind string) {
+		p := Pack{UUID: uuid, Version: version, Type: kind, SubPackName: subPack}
+		if i, ok := announced[uuid]; ok {
+			p.Size, p.HasScripts, p.Addon = i.Size, i.HasScripts, i.AddonPack
+			delete(announced, uuid)
+		}
+		packs = append(packs, p)
+	}
+	if stack != nil {
+		for _, p := range stack.BehaviourPacks {
+			add(p.UUID, p.Version, p.SubPackName, "behaviour")
+		}
+		for _, p := range stack.TexturePacks {
+			add(p.UUID, p.Version, p.SubPackName, "resource")
+		}
+	}
+	if info != nil {
+		for _, p := range info.TexturePacks {
+			if _, ok := announced[p.UUID.String()]; ok {
+				add(p.UUID.String(), p.Version, p.SubPackName, "unknown")
+			}
+		}
+	}
+	return packs
+}
+
+// PackExperiments returns the experiment toggles of the pack stack of the Session, sorted by name.
+func (s *Session) PackExperiments() []ManifestExperiment {
+	experiments := []ManifestExperiment{}
+	if stack, ok := Packet[*packet.ResourcePackStack](s); ok {
+		for _, e := range stack.Experiments {
+			experiments = append(experiments, ManifestExperiment{Name: e.Name, Enabled: e.Enabled})
+		}
+	}
+	slices.SortFunc(experiments, func(a, b ManifestExperiment) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return experiments
+}
+
+// Manifest holds information about the sessions that data was generated from. It is written to the output
+// directory alongside the generated data.
+type Manifest struct {
+	Sessions []ManifestSession `json:"sessions"`
+}
+
+// ManifestSession holds information about a single Session in the Manifest.
+type ManifestSession struct {
+	GameVersion     string               `json:"game_version"`
+	BaseGameVersion string               `json:"base_game_version"`
+	Experiments     []string             `json:"experiments"`
+	Packs           []Pack               `json:"packs"`
+	PackExperiments []ManifestExperiment `json:"pack_experiments"`
+}
+
+// ManifestExperiment holds an experiment toggle of the pack stack of a ManifestSession.
+type ManifestExperiment struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// NewManifest creates a Manifest for the sessions passed.
+func NewManifest(sessions []*Session) Manifest {
+	var m Manifest
+	for _, s := range sessions {
+		experiments := s.Experiments()
+		if experiments == nil {
+			experiments = []string{}
+		}
+		m.Sessions = append(m.Sessions, ManifestSession{
+			GameVersion:     s.GameVersion(),
+			BaseGameVersion: s.GameData.BaseGameVersion,
+			Experiments:     experiments,
+			Packs:           s.Packs(),
+			PackExperiments: s.PackExperiments(),
+		})
+	}
+	return m
+}
@@ -0,0 +1,408 @@
+package upgrade
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/df-mc/datagen/data"
+)
+
+// BlockSchema is a draft block state upgrade schema in the format of pmmp's BedrockBlockUpgradeSchema. All
+// property changes are indexed by the old name of the block, as the schema is applied to states of the old
+// palette.
+type BlockSchema struct {
+	MaxVersionMajor    int `json:"maxVersionMajor"`
+	MaxVersionMinor    int `json:"maxVersionMinor"`
+	MaxVersionPatch    int `json:"maxVersionPatch"`
+	MaxVersionRevision int `json:"maxVersionRevision"`
+
+	RenamedIDs                  map[string]string            `json:"renamedIds,omitempty"`
+	AddedProperties             map[string]map[string]Tag    `json:"addedProperties,omitempty"`
+	RemovedProperties           map[string][]string          `json:"removedProperties,omitempty"`
+	RenamedProperties           map[string]map[string]string `json:"renamedProperties,omitempty"`
+	RemappedPropertyValues      map[string]map[string]string `json:"remappedPropertyValues,omitempty"`
+	RemappedPropertyValuesIndex map[string][]ValueRemap      `json:"remappedPropertyValuesIndex,omitempty"`
+	FlattenedProperties         map[string]FlattenRule       `json:"flattenedProperties,omitempty"`
+}
+
+// Tag is a property value in an upgrade schema. Exactly one of its fields is set, depending on the type of the
+// property.
+type Tag struct {
+	Byte   *uint8  `json:"byte,omitempty"`
+	Int    *int32  `json:"int,omitempty"`
+	String *string `json:"string,omitempty"`
+}
+
+// ValueRemap maps an old value of a property to the value it has in the new palette.
+type ValueRemap struct {
+	Old Tag `json:"old"`
+	New Tag `json:"new"`
+}
+
+// FlattenRule describes a property that was flattened into the name of a block, such as the colour of wool. The
+// new name of the block is the prefix, followed by the (remapped) value of the property and the suffix.
+type FlattenRule struct {
+	Prefix                string            `json:"prefix"`
+	FlattenedProperty     string            `json:"flattenedProperty"`
+	FlattenedPropertyType string            `json:"flattenedPropertyType,omitempty"`
+	Suffix                string            `json:"suffix"`
+	FlattenedValueRemaps  map[string]string `json:"flattenedValueRemaps,omitempty"`
+}
+
+// NewBlockSchema compares the old and new palettes passed and creates a draft schema that upgrades the states of
+// the old palette to those of the new palette. Renamed blocks and flattened properties are detected using
+// heuristics, so the schema should always be reviewed. Changes that could not be expressed in the schema and
+// guesses that were ambiguous are returned as warnings.
+func NewBlockSchema(old, new *data.Palette) (BlockSchema, []string) {
+	var warnings []string
+	warnf := func(format string, a ...any) {
+		warnings = append(warnings, fmt.Sprintf(format, a...))
+	}
+	s := BlockSchema{
+		RenamedIDs:                  map[string]string{},
+		AddedProperties:             map[string]map[string]Tag{},
+		RemovedProperties:           map[string][]string{},
+		RenamedProperties:           map[string]map[string]string{},
+		RemappedPropertyValues:      map[string]map[string]string{},
+		RemappedPropertyValuesIndex: map[string][]ValueRemap{},
+		FlattenedProperties:         map[string]FlattenRule{},
+	}
+	_, _ = fmt.Sscanf(new.Version(), "%d.%d.%d.%d", &s.MaxVersionMajor, &s.MaxVersionMinor, &s.MaxVersionPatch, &s.MaxVersionRevision)
+
+	oldBlocks, newBlocks := blockTypes(old), blockTypes(new)
+	var added []string
+	for _, name := range slices.Sorted(maps.Keys(newBlocks)) {
+		if _, ok := oldBlocks[name]; !ok {
+			added = append(added, name)
+		}
+	}
+	used := map[string]bool{}
+
+	for _, name := range slices.Sorted(maps.Keys(oldBlocks)) {
+		b := oldBlocks[name]
+		target, persists := newBlocks[name]
+		if rule, property, to, ok := flatten(name, b, persists, newBlocks, added, used); ok {
+			s.FlattenedProperties[name] = rule
+			s.diffProperties(name, b.without(property), newBlocks[to], warnf)
+			continue
+		}
+		if !persists {
+			to, ok := rename(name, b, newBlocks, added, used, warnf)
+			if !ok {
+				warnf("block %s was removed and has no replacement", name)
+				continue
+			}
+			s.RenamedIDs[name], target = to, newBlocks[to]
+		}
+		s.diffProperties(name, b, target, warnf)
+	}
+	return s, warnings
+}
+
+// diffProperties compares the properties of the old block with the name passed to those of the new block it
+// is upgraded to, and adds the renamed, added and removed properties and remapped property values to the schema.
+// Values that cannot be remapped are reported to warnf.
+func (s BlockSchema) diffProperties(name string, old, new blockType, warnf func(format string, a ...any)) {
+	var removed, added []string
+	for _, p := range slices.Sorted(maps.Keys(old.properties)) {
+		if _, ok := new.properties[p]; !ok {
+			removed = append(removed, p)
+		}
+	}
+	for _, p := range slices.Sorted(maps.Keys(new.properties)) {
+		if _, ok := old.properties[p]; !ok {
+			added = append(added, p)
+		}
+	}
+	for _, p := range slices.Clone(removed) {
+		i := slices.IndexFunc(added, func(a string) bool {
+			return sameValues(old.properties[p], new.properties[a])
+		})
+		if i == -1 {
+			continue
+		}
+		setNested(s.RenamedProperties, name, p, added[i])
+		removed, added = slices.DeleteFunc(removed, func(r string) bool { return r == p }), slices.Delete(added, i, i+1)
+	}
+	if len(removed) > 0 {
+		s.RemovedProperties[name] = removed
+	}
+	for _, p := range added {
+		setNested(s.AddedProperties, name, p, newTag(new.defaults[p]))
+	}
+
+	for _, p := range slices.Sorted(maps.Keys(old.properties)) {
+		values, ok := new.properties[p]
+		if !ok || sameValues(old.properties[p], values) {
+			continue
+		}
+		oldOnly := slices.DeleteFunc(slices.Clone(old.properties[p]), func(v any) bool { return slices.Contains(values, v) })
+		newOnly := slices.DeleteFunc(slices.Clone(values), func(v any) bool { return slices.Contains(old.properties[p], v) })
+		if len(oldOnly) == 0 {
+			continue
+		} else if len(oldOnly) != len(newOnly) {
+			warnf("values %v of property %s of block %s cannot be remapped to %v", oldOnly, p, name, newOnly)
+			continue
+		}
+		remaps := make([]ValueRemap, len(oldOnly))
+		for i := range oldOnly {
+			remaps[i] = ValueRemap{Old: newTag(oldOnly[i]), New: newTag(newOnly[i])}
+		}
+		setNested(s.RemappedPropertyValues, name, p, s.remapIndex(p, remaps))
+	}
+}
+
+// remapIndex adds the value remaps passed to the remapped property values index of the schema, returning the key
+// under which they were added. Remaps equal to ones already in the index share the same key.
+func (s BlockSchema) remapIndex(property string, remaps []ValueRemap) string {
+	for i := 1; ; i++ {
+		key := property
+		if i > 1 {
+			key = fmt.Sprintf("%s_%d", property, i)
+		}
+		existing, ok := s.RemappedPropertyValuesIndex[key]
+		if !ok {
+			s.RemappedPropertyValuesIndex[key] = remaps
+			return key
+		} else if reflect.DeepEqual(existing, remaps) {
+			return key
+		}
+	}
+}
+
+// UpgradedName returns the name that the old block state with the name and properties passed has after applying
+// the schema, taking renamed blocks and flattened properties into account.
+func (s BlockSchema) UpgradedName(name string, properties map[string]any) string {
+	if rule, ok := s.FlattenedProperties[name]; ok {
+		value := fmt.Sprint(properties[rule.FlattenedProperty])
+		if remapped, ok := rule.FlattenedValueRemaps[value]; ok {
+			value = remapped
+		}
+		return rule.Prefix + value + rule.Suffix
+	}
+	if renamed, ok := s.RenamedIDs[name]; ok {
+		return renamed
+	}
+	return name
+}
+
+// blockType holds the properties of a block in a palette with all of their values in the order they first
+// appear, along with the default value of every property, which is its value in the first state of the block.
+type blockType struct {
+	properties map[string][]any
+	defaults   map[string]any
+}
+
+// without returns a copy of the blockType without the property passed.
+func (b blockType) without(property string) blockType {
+	c := blockType{properties: maps.Clone(b.properties), defaults: maps.Clone(b.defaults)}
+	delete(c.properties, property)
+	delete(c.defaults, property)
+	return c
+}
+
+// blockTypes returns the block types of all blocks in the palette passed, indexed by their name.
+func blockTypes(p *data.Palette) map[string]blockType {
+	m := map[string]blockType{}
+	for _, state := range p.States() {
+		b, ok := m[state.Name]
+		if !ok {
+			b = blockType{properties: map[string][]any{}, defaults: maps.Clone(state.Properties)}
+			m[state.Name] = b
+		}
+		for k, v := range state.Properties {
+			if !slices.Contains(b.properties[k], v) {
+				b.properties[k] = append(b.properties[k], v)
+			}
+		}
+	}
+	return m
+}
+
+// flatten attempts to find a property of the old block passed that was flattened into the names of new blocks,
+// which are either blocks added in the new palette or the block itself if it still exists. It returns the rule,
+// the flattened property and the name of one of the new blocks if successful.
+func flatten(name string, b blockType, persists bool, newBlocks map[string]blockType, added []string, used map[string]bool) (FlattenRule, string, string, bool) {
+	for _, property := range slices.Sorted(maps.Keys(b.properties)) {
+		if persists {
+			if _, ok := newBlocks[name].properties[property]; ok {
+				continue
+			}
+		}
+		rest := b.without(property)
+		var candidates []string
+		for _, c := range added {
+			if !used[c] && sameKeys(rest.properties, newBlocks[c].properties) {
+				candidates = append(candidates, c)
+			}
+		}
+		if persists && sameKeys(rest.properties, newBlocks[name].properties) {
+			candidates = append(candidates, name)
+		}
+
+		rule, ok := flattenRule(property, b.properties[property], candidates)
+		if !ok {
+			continue
+		}
+		var to string
+		for _, v := range b.properties[property] {
+			value := fmt.Sprint(v)
+			if remapped, ok := rule.FlattenedValueRemaps[value]; ok {
+				value = remapped
+			}
+			to = rule.Prefix + value + rule.Suffix
+			used[to] = true
+		}
+		return rule, property, to, true
+	}
+	return FlattenRule{}, "", "", false
+}
+
+// flattenRule attempts to create a FlattenRule that maps every value passed to one of the candidate names. The
+// prefix and suffix are derived from the first value found in exactly one candidate. A single value that does
+// not produce a candidate is remapped if exactly one candidate with the same prefix and suffix is left.
+func flattenRule(property string, values []any, candidates []string) (FlattenRule, bool) {
+	if len(values) < 2 {
+		return FlattenRule{}, false
+	}
+	rule := FlattenRule{FlattenedProperty: property, FlattenedValueRemaps: map[string]string{}}
+	switch values[0].(type) {
+	case uint8:
+		rule.FlattenedPropertyType = "byte"
+	case int32:
+		rule.FlattenedPropertyType = "int"
+	}
+	found := false
+	for _, v := range values {
+		value := fmt.Sprint(v)
+		matches := slices.DeleteFunc(slices.Clone(candidates), func(c string) bool {
+			return !strings.Contains(c[strings.Index(c, ":")+1:], value)
+		})
+		if len(matches) == 1 {
+			i := strings.LastIndex(matches[0], value)
+			rule.Prefix, rule.Suffix, found = matches[0][:i], matches[0][i+len(value):], true
+			break
+		}
+	}
+	if !found {
+		return FlattenRule{}, false
+	}
+
+	var unmatched []string
+	left := slices.DeleteFunc(slices.Clone(candidates), func(c string) bool {
+		return len(c) <= len(rule.Prefix)+len(rule.Suffix) || !strings.HasPrefix(c, rule.Prefix) || !strings.HasSuffix(c, rule.Suffix)
+	})
+	for _, v := range values {
+		name := rule.Prefix + fmt.Sprint(v) + rule.Suffix
+		if i := slices.Index(left, name); i != -1 {
+			left = slices.Delete(left, i, i+1)
+			continue
+		}
+		unmatched = append(unmatched, fmt.Sprint(v))
+	}
+	if len(unmatched) > 1 || len(unmatched) == 1 && len(left) != 1 || len(unmatched) == len(values)-1 {
+		return FlattenRule{}, false
+	}
+	if len(unmatched) == 1 {
+		rule.FlattenedValueRemaps[unmatched[0]] = strings.TrimSuffix(strings.TrimPrefix(left[0], rule.Prefix), rule.Suffix)
+	}
+	return rule, true
+}
+
+// rename attempts to find the block added in the new palette that the removed block passed was renamed to. Added
+// blocks with the same properties and values are preferred over those with only the same properties. If there
+// are multiple candidates, the one sharing the most words with the old name is chosen, which is reported to warnf
+// if other candidates share as many words. A sole candidate is always chosen, but multiple candidates of which
+// none share any words with the old name are not.
+func rename(name string, b blockType, newBlocks map[string]blockType, added []string, used map[string]bool, warnf func(format string, a ...any)) (string, bool) {
+	for _, same := range []func(blockType) bool{
+		func(n blockType) bool {
+			return sameKeys(b.properties, n.properties) && sameValueSets(b.properties, n.properties)
+		},
+		func(n blockType) bool { return sameKeys(b.properties, n.properties) },
+	} {
+		var best string
+		score, ties := -1, 0
+		for _, c := range added {
+			if used[c] || !same(newBlocks[c]) {
+				continue
+			}
+			switch sc := sharedWords(name, c); {
+			case sc > score:
+				best, score, ties = c, sc, 0
+			case sc == score:
+				ties++
+			}
+		}
+		if best == "" || score == 0 && ties > 0 {
+			continue
+		}
+		if ties > 0 {
+			warnf("block %s has multiple candidates for its new name, chose %s", name, best)
+		}
+		used[best] = true
+		return best, true
+	}
+	return "", false
+}
+
+// sharedWords returns the number of words separated by underscores that the names passed have in common,
+// ignoring their namespace.
+func sharedWords(a, b string) int {
+	wordsA := strings.Split(a[strings.Index(a, ":")+1:], "_")
+	wordsB := strings.Split(b[strings.Index(b, ":")+1:], "_")
+	n := 0
+	for _, w := range wordsA {
+		if slices.Contains(wordsB, w) {
+			n++
+		}
+	}
+	return n
+}
+
+// sameKeys checks if the property maps passed hold the same properties.
+func sameKeys(a, b map[string][]any) bool {
+	return len(a) == len(b) && !slices.ContainsFunc(slices.Collect(maps.Keys(a)), func(k string) bool {
+		_, ok := b[k]
+		return !ok
+	})
+}
+
+// sameValueSets checks if every property in the property maps passed has the same values in both.
+func sameValueSets(a, b map[string][]any) bool {
+	for k, v := range a {
+		if !sameValues(v, b[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameValues checks if the value lists passed hold the same values, regardless of their order.
+func sameValues(a, b []any) bool {
+	return len(a) == len(b) && !slices.ContainsFunc(a, func(v any) bool { return !slices.Contains(b, v) })
+}
+
+// newTag returns the Tag holding the property value passed.
+func newTag(v any) Tag {
+	switch v := v.(type) {
+	case uint8:
+		return Tag{Byte: &v}
+	case int32:
+		return Tag{Int: &v}
+	case string:
+		return Tag{String: &v}
+	}
+	panic(fmt.Errorf("unexpected property value of type %T", v))
+}
+
+// setNested sets the value at the keys passed in the nested map m, creating the inner map if needed.
+func setNested[V any](m map[string]map[string]V, outer, inner string, v V) {
+	if _, ok := m[outer]; !ok {
+		m[outer] = map[string]V{}
+	}
+	m[outer][inner] = v
+}
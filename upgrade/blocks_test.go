@@ -0,0 +1,218 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/df-mc/datagen/data"
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
+)
+
+// state returns a BlockState with the name passed and properties set from the key-value pairs passed.
+func state(name string, properties ...any) data.BlockState {
+	s := data.BlockState{Name: name, Properties: map[string]any{}}
+	for i := 0; i < len(properties); i += 2 {
+		s.Properties[properties[i].(string)] = properties[i+1]
+	}
+	return s
+}
+
+// testPalette creates a Palette with the states passed using data.ReadPalette. Every state is placed by the item
+// with the name of its block, with meta values counting up from 0 for every block.
+func testPalette(t *testing.T, version int32, states ...data.BlockState) *data.Palette {
+	t.Helper()
+	var blockStates []byte
+	metas := make([]int32, 0, len(states))
+	counts := map[string]int32{}
+	for _, s := range states {
+		b, err := nbt.Marshal(map[string]any{"name": s.Name, "states": s.Properties, "version": version})
+		if err != nil {
+			t.Fatal(err)
+		}
+		blockStates = append(blockStates, b...)
+		metas = append(metas, counts[s.Name])
+		counts[s.Name]++
+	}
+	metaMap, err := json.Marshal(metas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := data.ReadPalette(blockStates, metaMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestNewBlockSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new []data.BlockState
+		want     BlockSchema
+		warnings []string
+	}{
+		{
+			name: "unchanged",
+			old:  []data.BlockState{state("minecraft:stone")},
+			new:  []data.BlockState{state("minecraft:stone")},
+		},
+		{
+			name: "rename_sole_candidate",
+			old:  []data.BlockState{state("minecraft:stone"), state("minecraft:foo")},
+			new:  []data.BlockState{state("minecraft:stone"), state("minecraft:bar")},
+			want: BlockSchema{RenamedIDs: map[string]string{"minecraft:foo": "minecraft:bar"}},
+		},
+		{
+			name: "rename_shared_words",
+			old:  []data.BlockState{state("minecraft:old_log")},
+			new:  []data.BlockState{state("minecraft:stone"), state("minecraft:oak_log")},
+			want: BlockSchema{RenamedIDs: map[string]string{"minecraft:old_log": "minecraft:oak_log"}},
+		},
+		{
+			name: "rename_same_values_preferred",
+			old:  []data.BlockState{state("minecraft:a", "age", int32(0)), state("minecraft:a", "age", int32(1))},
+			new: []data.BlockState{
+				state("minecraft:b", "age", int32(0)), state("minecraft:b", "age", int32(1)), state("minecraft:b", "age", int32(2)),
+				state("minecraft:c", "age", int32(0)), state("minecraft:c", "age", int32(1)),
+			},
+			want: BlockSchema{RenamedIDs: map[string]string{"minecraft:a": "minecraft:c"}},
+		},
+		{
+			name:     "rename_tie",
+			old:      []data.BlockState{state("minecraft:red_thing")},
+			new:      []data.BlockState{state("minecraft:red_a"), state("minecraft:red_b")},
+			want:     BlockSchema{RenamedIDs: map[string]string{"minecraft:red_thing": "minecraft:red_a"}},
+			warnings: []string{"block minecraft:red_thing has multiple candidates for its new name, chose minecraft:red_a"},
+		},
+		{
+			name:     "removed_ambiguous",
+			old:      []data.BlockState{state("minecraft:foo")},
+			new:      []data.BlockState{state("minecraft:bar"), state("minecraft:baz")},
+			warnings: []string{"block minecraft:foo was removed and has no replacement"},
+		},
+		{
+			name:     "removed_no_candidate",
+			old:      []data.BlockState{state("minecraft:foo", "age", int32(0))},
+			new:      []data.BlockState{state("minecraft:bar")},
+			warnings: []string{"block minecraft:foo was removed and has no replacement"},
+		},
+		{
+			name: "flatten",
+			old:  []data.BlockState{state("minecraft:wool", "color", "white"), state("minecraft:wool", "color", "red")},
+			new:  []data.BlockState{state("minecraft:white_wool"), state("minecraft:red_wool")},
+			want: BlockSchema{FlattenedProperties: map[string]FlattenRule{"minecraft:wool": {
+				Prefix: "minecraft:", FlattenedProperty: "color", Suffix: "_wool",
+			}}},
+		},
+		{
+			name: "flatten_remapped_value",
+			old: []data.BlockState{
+				state("minecraft:wool", "color", "white"), state("minecraft:wool", "color", "red"), state("minecraft:wool", "color", "silver"),
+			},
+			new: []data.BlockState{state("minecraft:white_wool"), state("minecraft:red_wool"), state("minecraft:light_gray_wool")},
+			want: BlockSchema{FlattenedProperties: map[string]FlattenRule{"minecraft:wool": {
+				Prefix: "minecraft:", FlattenedProperty: "color", Suffix: "_wool",
+				FlattenedValueRemaps: map[string]string{"silver": "light_gray"},
+			}}},
+		},
+		{
+			name: "flatten_remaining_properties",
+			old: []data.BlockState{
+				state("minecraft:log", "type", "oak", "axis", "y"), state("minecraft:log", "type", "oak", "axis", "x"),
+				state("minecraft:log", "type", "birch", "axis", "y"), state("minecraft:log", "type", "birch", "axis", "x"),
+			},
+			new: []data.BlockState{
+				state("minecraft:oak_log", "axis", "y"), state("minecraft:oak_log", "axis", "x"),
+				state("minecraft:birch_log", "axis", "y"), state("minecraft:birch_log", "axis", "x"),
+			},
+			want: BlockSchema{
+				FlattenedProperties: map[string]FlattenRule{"minecraft:log": {Prefix: "minecraft:", FlattenedProperty: "type", Suffix: "_log"}},
+			},
+		},
+		{
+			name: "properties",
+			old: []data.BlockState{
+				state("minecraft:a", "facing", "north", "open", uint8(0)), state("minecraft:a", "facing", "south", "open", uint8(1)),
+			},
+			new: []data.BlockState{
+				state("minecraft:a", "direction", "north", "lit", int32(3)), state("minecraft:a", "direction", "south", "lit", int32(4)),
+			},
+			want: BlockSchema{
+				RenamedProperties: map[string]map[string]string{"minecraft:a": {"facing": "direction"}},
+				RemovedProperties: map[string][]string{"minecraft:a": {"open"}},
+				AddedProperties:   map[string]map[string]Tag{"minecraft:a": {"lit": newTag(int32(3))}},
+			},
+		},
+		{
+			name: "remapped_values",
+			old: []data.BlockState{
+				state("minecraft:a", "half", "top"), state("minecraft:a", "half", "bottom"),
+				state("minecraft:b", "half", "top"), state("minecraft:b", "half", "bottom"),
+			},
+			new: []data.BlockState{
+				state("minecraft:a", "half", "upper"), state("minecraft:a", "half", "bottom"),
+				state("minecraft:b", "half", "upper"), state("minecraft:b", "half", "bottom"),
+			},
+			want: BlockSchema{
+				RemappedPropertyValues:      map[string]map[string]string{"minecraft:a": {"half": "half"}, "minecraft:b": {"half": "half"}},
+				RemappedPropertyValuesIndex: map[string][]ValueRemap{"half": {{Old: newTag("top"), New: newTag("upper")}}},
+			},
+		},
+		{
+			name: "unremappable_values",
+			old:  []data.BlockState{state("minecraft:a", "half", "top"), state("minecraft:a", "half", "bottom")},
+			new: []data.BlockState{
+				state("minecraft:a", "half", "upper"), state("minecraft:a", "half", "lower"), state("minecraft:a", "half", "middle"),
+			},
+			warnings: []string{"values [top bottom] of property half of block minecraft:a cannot be remapped to [upper lower middle]"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, warnings := NewBlockSchema(testPalette(t, 0, tt.old...), testPalette(t, 0, tt.new...))
+			// Empty maps are omitted from the JSON form of the schema, so comparing the JSON ignores the
+			// difference between empty and nil maps.
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(tt.want)
+			if string(gotJSON) != string(wantJSON) {
+				t.Errorf("got schema %s, want %s", gotJSON, wantJSON)
+			}
+			if !slices.Equal(warnings, tt.warnings) {
+				t.Errorf("got warnings %q, want %q", warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestBlockSchemaVersion(t *testing.T) {
+	s, _ := NewBlockSchema(testPalette(t, 0, state("minecraft:stone")), testPalette(t, 1<<24|21<<16|60<<8|33, state("minecraft:stone")))
+	if s.MaxVersionMajor != 1 || s.MaxVersionMinor != 21 || s.MaxVersionPatch != 60 || s.MaxVersionRevision != 33 {
+		t.Errorf("got version %d.%d.%d.%d, want 1.21.60.33", s.MaxVersionMajor, s.MaxVersionMinor, s.MaxVersionPatch, s.MaxVersionRevision)
+	}
+}
+
+func TestUpgradedName(t *testing.T) {
+	s := BlockSchema{
+		RenamedIDs: map[string]string{"minecraft:foo": "minecraft:bar"},
+		FlattenedProperties: map[string]FlattenRule{"minecraft:wool": {
+			Prefix: "minecraft:", FlattenedProperty: "color", Suffix: "_wool",
+			FlattenedValueRemaps: map[string]string{"silver": "light_gray"},
+		}},
+	}
+	tests := []struct {
+		name       string
+		properties map[string]any
+		want       string
+	}{
+		{"minecraft:stone", nil, "minecraft:stone"},
+		{"minecraft:foo", nil, "minecraft:bar"},
+		{"minecraft:wool", map[string]any{"color": "red"}, "minecraft:red_wool"},
+		{"minecraft:wool", map[string]any{"color": "silver"}, "minecraft:light_gray_wool"},
+	}
+	for _, tt := range tests {
+		if got := s.UpgradedName(tt.name, tt.properties); got != tt.want {
+			t.Errorf("UpgradedName(%s, %v) = %s, want %s", tt.name, tt.properties, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package upgrade
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/df-mc/datagen/data"
+)
+
+// ItemSchema is a draft item upgrade schema in the format of pmmp's BedrockItemUpgradeSchema. RemappedMetas maps
+// specific meta values of an old item to a new item, which then has a meta value of 0, and takes precedence over
+// RenamedIDs.
+type ItemSchema struct {
+	RenamedIDs    map[string]string            `json:"renamedIds,omitempty"`
+	RemappedMetas map[string]map[string]string `json:"remappedMetas,omitempty"`
+}
+
+// ItemUpgrade is the dragonfly form of the upgrades of a single old item in an ItemSchema. Name is the new name
+// of the item, if it was renamed, and Metas holds the new names of the item for specific meta values.
+type ItemUpgrade struct {
+	Name  string            `nbt:"name,omitempty"`
+	Metas map[string]string `nbt:"metas,omitempty"`
+}
+
+// NewItemSchema compares the old and new item registries passed and creates a draft schema that upgrades the items
+// of the old registry to those of the new registry. Block items are upgraded using the block states they place in
+// the old palette, upgraded using a BlockSchema between the palettes, so that items split or flattened from meta
+// values are remapped to the new items of their blocks. Other removed items are renamed to an added item only if
+// it is the single added item containing every word of the shorter of both names, so the schema should still be
+// reviewed. Removed items without such a replacement are returned as warnings, along with the best guess for their
+// new name if there is one.
+func NewItemSchema(oldItems, newItems []string, old, new *data.Palette) (ItemSchema, []string) {
+	var warnings []string
+	s := ItemSchema{RenamedIDs: map[string]string{}, RemappedMetas: map[string]map[string]string{}}
+	blocks, _ := NewBlockSchema(old, new)
+
+	var added []string
+	for _, name := range newItems {
+		if !slices.Contains(oldItems, name) {
+			added = append(added, name)
+		}
+	}
+	slices.Sort(added)
+	used := map[string]bool{}
+	upgraded := func(name string, meta int32) string {
+		state, ok := old.ItemMetaBlockState(name, meta)
+		if !ok {
+			return ""
+		}
+		blockName, _ := state["name"].(string)
+		properties, _ := state["states"].(map[string]any)
+		if n := blocks.UpgradedName(blockName, properties); slices.Contains(newItems, n) {
+			return n
+		}
+		return ""
+	}
+
+	// Items are first upgraded using the blocks they place, so that the added items taken by them are not used
+	// to guess the new names of other removed items.
+	var removed []string
+	for _, name := range slices.Sorted(slices.Values(oldItems)) {
+		metas := old.ItemMetas(name)
+		base := name
+		if !slices.Contains(newItems, name) {
+			if base = ""; len(metas) > 0 {
+				base = upgraded(name, metas[0])
+			}
+			if base == "" {
+				removed = append(removed, name)
+				continue
+			}
+			s.RenamedIDs[name], used[base] = base, true
+		}
+		for _, meta := range metas {
+			if n := upgraded(name, meta); n != "" && n != base {
+				setNested(s.RemappedMetas, name, strconv.Itoa(int(meta)), n)
+				used[n] = true
+			}
+		}
+	}
+	for _, name := range removed {
+		to, certain := renameItem(name, added, used)
+		switch {
+		case to == "":
+			warnings = append(warnings, fmt.Sprintf("item %s was removed and has no replacement", name))
+		case !certain:
+			warnings = append(warnings, fmt.Sprintf("item %s was removed and has no certain replacement, it may have been renamed to %s", name, to))
+		default:
+			s.RenamedIDs[name], used[to] = to, true
+		}
+	}
+	return s, warnings
+}
+
+// Dragonfly returns the upgrades of the ItemSchema in the form used by dragonfly, indexed by the old name of the
+// item.
+func (s ItemSchema) Dragonfly() map[string]ItemUpgrade {
+	m := map[string]ItemUpgrade{}
+	for _, name := range slices.Concat(slices.Collect(maps.Keys(s.RenamedIDs)), slices.Collect(maps.Keys(s.RemappedMetas))) {
+		m[name] = ItemUpgrade{Name: s.RenamedIDs[name], Metas: s.RemappedMetas[name]}
+	}
+	return m
+}
+
+// renameItem returns the unused added item sharing the most words with the name of the removed item passed, or
+// an empty string if no added item shares any words with it. True is returned only if the added item shares every
+// word of the shorter of both names, such as minecraft:iron_sword for minecraft:old_iron_sword, and no other
+// added item shares as many words.
+func renameItem(name string, added []string, used map[string]bool) (string, bool) {
+	var best string
+	score, ties := 0, 0
+	for _, c := range added {
+		if used[c] {
+			continue
+		}
+		switch sc := sharedWords(name, c); {
+		case sc > score:
+			best, score, ties = c, sc, 0
+		case sc == score && sc > 0:
+			ties++
+		}
+	}
+	return best, best != "" && ties == 0 && score == min(wordCount(name), wordCount(best))
+}
+
+// wordCount returns the number of words in the name passed without its namespace, as compared by sharedWords.
+func wordCount(name string) int {
+	return strings.Count(name[strings.Index(name, ":")+1:], "_") + 1
+}
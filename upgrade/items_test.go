@@ -0,0 +1,91 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestNewItemSchema(t *testing.T) {
+	old := testPalette(t, 0,
+		state("minecraft:wool", "color", "white"), state("minecraft:wool", "color", "red"),
+		state("minecraft:log", "axis", "y"),
+	)
+	new := testPalette(t, 0,
+		state("minecraft:white_wool"), state("minecraft:red_wool"),
+		state("minecraft:log", "axis", "y"),
+	)
+	oldItems := []string{
+		"minecraft:wool", "minecraft:log", "minecraft:stick", "minecraft:old_iron_sword", "minecraft:old_sword", "minecraft:gone",
+	}
+	newItems := []string{
+		"minecraft:white_wool", "minecraft:red_wool", "minecraft:log", "minecraft:stick", "minecraft:iron_sword", "minecraft:stone_sword",
+	}
+
+	got, warnings := NewItemSchema(oldItems, newItems, old, new)
+	want := ItemSchema{
+		RenamedIDs: map[string]string{
+			"minecraft:wool":           "minecraft:white_wool",
+			"minecraft:old_iron_sword": "minecraft:iron_sword",
+		},
+		RemappedMetas: map[string]map[string]string{"minecraft:wool": {"1": "minecraft:red_wool"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		gotJSON, _ := json.Marshal(got)
+		wantJSON, _ := json.Marshal(want)
+		t.Errorf("got schema %s, want %s", gotJSON, wantJSON)
+	}
+	wantWarnings := []string{
+		"item minecraft:gone was removed and has no replacement",
+		"item minecraft:old_sword was removed and has no certain replacement, it may have been renamed to minecraft:stone_sword",
+	}
+	if !slices.Equal(warnings, wantWarnings) {
+		t.Errorf("got warnings %q, want %q", warnings, wantWarnings)
+	}
+}
+
+func TestRenameItem(t *testing.T) {
+	tests := []struct {
+		name    string
+		added   []string
+		used    map[string]bool
+		want    string
+		certain bool
+	}{
+		// Every word of the shorter name is shared with a single added item.
+		{"minecraft:old_iron_sword", []string{"minecraft:iron_sword", "minecraft:stone_sword"}, nil, "minecraft:iron_sword", true},
+		{"minecraft:sword", []string{"minecraft:iron_sword", "minecraft:stone"}, nil, "minecraft:iron_sword", true},
+		{"minecraft:sword", []string{"minecraft:iron_sword", "minecraft:stone_sword"}, map[string]bool{"minecraft:iron_sword": true}, "minecraft:stone_sword", true},
+		// A single shared word is only a guess.
+		{"minecraft:old_sword", []string{"minecraft:stone", "minecraft:iron_sword"}, nil, "minecraft:iron_sword", false},
+		// Multiple added items share every word of the shorter name.
+		{"minecraft:sword", []string{"minecraft:iron_sword", "minecraft:stone_sword"}, nil, "minecraft:iron_sword", false},
+		{"minecraft:foo", []string{"minecraft:bar"}, nil, "", false},
+		{"minecraft:foo", nil, nil, "", false},
+	}
+	for _, tt := range tests {
+		got, certain := renameItem(tt.name, tt.added, tt.used)
+		if got != tt.want || certain != tt.certain {
+			t.Errorf("renameItem(%s, %v, %v) = %q, %v, want %q, %v", tt.name, tt.added, tt.used, got, certain, tt.want, tt.certain)
+		}
+	}
+}
+
+func TestItemSchemaDragonfly(t *testing.T) {
+	s := ItemSchema{
+		RenamedIDs: map[string]string{"minecraft:wool": "minecraft:white_wool", "minecraft:old_sword": "minecraft:iron_sword"},
+		RemappedMetas: map[string]map[string]string{
+			"minecraft:wool": {"1": "minecraft:red_wool"},
+			"minecraft:log":  {"1": "minecraft:spruce_log"},
+		},
+	}
+	want := map[string]ItemUpgrade{
+		"minecraft:wool":      {Name: "minecraft:white_wool", Metas: map[string]string{"1": "minecraft:red_wool"}},
+		"minecraft:old_sword": {Name: "minecraft:iron_sword"},
+		"minecraft:log":       {Metas: map[string]string{"1": "minecraft:spruce_log"}},
+	}
+	if got := s.Dragonfly(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,163 @@
+package write
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
+)
+
+// FS is a file system that generated data is written to. DiskFS writes all data to disk, while a MemFS holds
+// the data in memory, so that it may be compared with the data already on disk.
+type FS interface {
+	// WriteFile writes the data passed to the file at the path passed, creating any parent directories that
+	// do not yet exist.
+	WriteFile(path string, b []byte) error
+}
+
+// DiskFS is an FS that writes directly to disk, printing the path of every file written.
+type DiskFS struct{}
+
+// WriteFile ...
+func (DiskFS) WriteFile(path string, b []byte) error {
+	fmt.Println("Writing", path)
+	_ = os.MkdirAll(filepath.Dir(path), 0755)
+	return os.WriteFile(path, b, 0644)
+}
+
+// Sub returns an FS that writes files to the directory passed within fsys, with paths relative to that directory.
+func Sub(fsys FS, dir string) FS {
+	return subFS{fsys: fsys, dir: dir}
+}
+
+// subFS is an FS returned by Sub.
+type subFS struct {
+	fsys FS
+	dir  string
+}
+
+// WriteFile ...
+func (s subFS) WriteFile(path string, b []byte) error {
+	return s.fsys.WriteFile(filepath.Join(s.dir, path), b)
+}
+
+// MemFS is an FS that holds all files written to it in memory.
+type MemFS struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+// NewMemFS returns a new, empty MemFS.
+func NewMemFS() *MemFS {
+	return &MemFS{files: make(map[string][]byte)}
+}
+
+// WriteFile ...
+func (m *MemFS) WriteFile(path string, b []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[filepath.Clean(path)] = slices.Clone(b)
+	return nil
+}
+
+// Diff compares the files written to the MemFS with the files currently found in the directory passed. Only
+// files written to a path within dir are taken into account.
+func (m *MemFS) Diff(dir string) (Diff, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dir = filepath.Clean(dir)
+	var d Diff
+	for path, b := range m.files {
+		if rel, err := filepath.Rel(dir, path); err != nil || !filepath.IsLocal(rel) {
+			continue
+		}
+		existing, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			d.Created = append(d.Created, path)
+		case err != nil:
+			return Diff{}, fmt.Errorf("read %s: %w", path, err)
+		case bytes.Equal(existing, b) || equalNBT(path, existing, b):
+			d.Unchanged = append(d.Unchanged, path)
+		default:
+			d.Changed = append(d.Changed, path)
+		}
+	}
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		if _, ok := m.files[path]; !ok {
+			d.Removed = append(d.Removed, path)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return Diff{}, fmt.Errorf("walk %s: %w", dir, err)
+	}
+	slices.Sort(d.Created)
+	slices.Sort(d.Changed)
+	slices.Sort(d.Unchanged)
+	slices.Sort(d.Removed)
+	return d, nil
+}
+
+// equalNBT checks if a and b hold the same NBT data if the path passed is that of an NBT file. The NBT encoder
+// does not write the entries of maps in a fixed order, so NBT files with the same contents may differ in bytes.
+func equalNBT(path string, a, b []byte) bool {
+	if filepath.Ext(path) != ".nbt" {
+		return false
+	}
+	var va, vb any
+	if err := nbt.Unmarshal(a, &va); err != nil {
+		return false
+	}
+	if err := nbt.Unmarshal(b, &vb); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// Diff holds the differences between the files held by a MemFS and the files found on disk.
+type Diff struct {
+	// Created holds the paths of files that do not yet exist on disk.
+	Created []string
+	// Changed holds the paths of files that exist on disk, but with different contents.
+	Changed []string
+	// Unchanged holds the paths of files that exist on disk with the same contents.
+	Unchanged []string
+	// Removed holds the paths of files that exist on disk, but that were not written to the MemFS.
+	Removed []string
+}
+
+// Empty returns true if no files would be created, changed or removed.
+func (d Diff) Empty() bool {
+	return len(d.Created) == 0 && len(d.Changed) == 0 && len(d.Removed) == 0
+}
+
+// Print writes a human-readable summary of the Diff to the io.Writer passed.
+func (d Diff) Print(w io.Writer) {
+	for _, section := range []struct {
+		name  string
+		paths []string
+	}{
+		{"Created", d.Created},
+		{"Changed", d.Changed},
+		{"Unchanged", d.Unchanged},
+		{"Removed", d.Removed},
+	} {
+		for _, path := range section.paths {
+			_, _ = fmt.Fprintln(w, section.name, path)
+		}
+	}
+	_, _ = fmt.Fprintf(w, "%d created, %d changed, %d unchanged, %d removed\n", len(d.Created), len(d.Changed), len(d.Unchanged), len(d.Removed))
+}
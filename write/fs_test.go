@@ -0,0 +1,133 @@
+package write
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
+)
+
+func TestMemFSDiff(t *testing.T) {
+	dir := t.TempDir()
+	ab, err := nbt.Marshal(struct {
+		A uint8 `nbt:"a"`
+		B uint8 `nbt:"b"`
+	}{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ba, err := nbt.Marshal(struct {
+		B uint8 `nbt:"b"`
+		A uint8 `nbt:"a"`
+	}{2, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.Equal(ab, ba) {
+		t.Fatal("NBT encodings with different entry order are equal in bytes")
+	}
+	onDisk := map[string][]byte{
+		"changed.json":        []byte(`{"a": 1}`),
+		"unchanged.json":      []byte(`{"a": 1}`),
+		"removed.json":        []byte(`{"a": 1}`),
+		"reordered.nbt":       ab,
+		"nested/changed.nbt":  ab,
+		"renamed/removed.nbt": ab,
+	}
+	for name, b := range onDisk {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, b, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := NewMemFS()
+	written := map[string][]byte{
+		"changed.json":       []byte(`{"a": 2}`),
+		"unchanged.json":     []byte(`{"a": 1}`),
+		"created.json":       []byte(`{}`),
+		"reordered.nbt":      ba,
+		"nested/changed.nbt": ba[:len(ba)-1],
+	}
+	for name, b := range written {
+		if err := m.WriteFile(filepath.Join(dir, name), b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Files written outside the directory compared must be ignored.
+	_ = m.WriteFile(filepath.Join(t.TempDir(), "outside.json"), nil)
+
+	d, err := m.Diff(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := func(names ...string) []string {
+		var p []string
+		for _, name := range names {
+			p = append(p, filepath.Join(dir, name))
+		}
+		return p
+	}
+	for _, tt := range []struct {
+		name      string
+		got, want []string
+	}{
+		{"created", d.Created, paths("created.json")},
+		{"changed", d.Changed, paths("changed.json", "nested/changed.nbt")},
+		{"unchanged", d.Unchanged, paths("reordered.nbt", "unchanged.json")},
+		{"removed", d.Removed, paths("removed.json", "renamed/removed.nbt")},
+	} {
+		if !slices.Equal(tt.got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	if d.Empty() {
+		t.Error("diff with created, changed and removed files is empty")
+	}
+}
+
+func TestDiffEmpty(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.json")
+	if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := NewMemFS()
+	_ = m.WriteFile(path, []byte("{}"))
+	d, err := m.Diff(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Empty() {
+		t.Errorf("diff of identical directory is not empty: %+v", d)
+	}
+}
+
+func TestDiffMissingDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "output")
+	m := NewMemFS()
+	_ = m.WriteFile(filepath.Join(dir, "data.json"), []byte("{}"))
+	d, err := m.Diff(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Created) != 1 || len(d.Removed) != 0 {
+		t.Errorf("diff against missing directory: got %+v", d)
+	}
+}
+
+func TestSub(t *testing.T) {
+	m := NewMemFS()
+	if err := Sub(Sub(m, "output"), "dragonfly").WriteFile("vanilla/items.go", []byte("package vanilla")); err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join("output", "dragonfly", "vanilla", "items.go")
+	if _, ok := m.files[want]; !ok {
+		t.Errorf("file not written to %s: %v", want, m.files)
+	}
+}
@@ -3,32 +3,28 @@ package write
 import (
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
 
 	"github.com/sandertv/gophertunnel/minecraft/nbt"
 )
 
-func JSON(path string, v any) {
+func JSON(fsys FS, path string, v any) {
 	b, err := json.MarshalIndent(v, "", "    ")
 	if err != nil {
 		panic(fmt.Errorf("failed to marshal data for %s: %w", path, err))
 	}
-	Raw(path, b)
+	Raw(fsys, path, b)
 }
 
-func NBT(path string, v any) {
+func NBT(fsys FS, path string, v any) {
 	b, err := nbt.Marshal(v)
 	if err != nil {
 		panic(fmt.Errorf("failed to marshal data for %s: %w", path, err))
 	}
-	Raw(path, b)
+	Raw(fsys, path, b)
 }
 
-func Raw(path string, b []byte) {
-	fmt.Println("Writing", path)
-	_ = os.MkdirAll(filepath.Dir(path), 0755)
-	err := os.WriteFile(path, b, 0644)
+func Raw(fsys FS, path string, b []byte) {
+	err := fsys.WriteFile(path, b)
 	if err != nil {
 		panic(fmt.Errorf("failed to write data to %s: %w", path, err))
 	}
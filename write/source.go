@@ -0,0 +1,25 @@
+package write
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"text/template"
+)
+
+// sourceHeader is written at the top of every Go source file generated by the tool.
+const sourceHeader = "// Code generated by github.com/df-mc/datagen. DO NOT EDIT.\n\n"
+
+// Go executes the template passed with the data v and writes the result to path in fsys as a Go source file. The
+// source is formatted using gofmt before it is written.
+func Go(fsys FS, path string, tmpl *template.Template, v any) {
+	buf := bytes.NewBufferString(sourceHeader)
+	if err := tmpl.Execute(buf, v); err != nil {
+		panic(fmt.Errorf("failed to execute template for %s: %w", path, err))
+	}
+	b, err := format.Source(buf.Bytes())
+	if err != nil {
+		panic(fmt.Errorf("failed to format source for %s: %w", path, err))
+	}
+	Raw(fsys, path, b)
+}